@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/xray"
 
 	//"github.com/aws/aws-sdk-go-v2/session"
@@ -34,6 +35,15 @@ func CreateAppSignalsClient(ctx context.Context, settings awsds.AWSDatasourceSet
 	return applicationsignals.NewFromConfig(cfg), nil
 }
 
+// CreateCloudWatchClient creates a CloudWatch client to get the metrics Application Signals references
+func CreateCloudWatchClient(ctx context.Context, settings awsds.AWSDatasourceSettings, backendSettings backend.DataSourceInstanceSettings) (*cloudwatch.Client, error) {
+	cfg, err := getAWSConfig(ctx, settings, backendSettings)
+	if err != nil {
+		return nil, err
+	}
+	return cloudwatch.NewFromConfig(cfg), nil
+}
+
 func getAWSConfig(ctx context.Context, settings awsds.AWSDatasourceSettings, backendSettings backend.DataSourceInstanceSettings) (aws.Config, error) {
 	region := settings.Region
 	if region == "" || region == "default" {
@@ -21,9 +21,26 @@ const (
 	QueryGetAnalyticsStatusCode                   = "getAnalyticsStatusCode"
 	QueryGetInsights                              = "getInsights"
 	QueryGetServiceMap                            = "getServiceMap"
+	QueryGetHistogram                             = "getHistogram"
+	QueryGetAlertingMetric                        = "getAlertingMetric"
+	QueryGetTraceRate                             = "getTraceRate"
+	QueryGetInsightDetails                        = "getInsightDetails"
+	QueryGetInsightAnnotations                    = "getInsightAnnotations"
+	QueryGetGroups                                = "getGroups"
+	QueryGetSamplingRules                         = "getSamplingRules"
+	QueryGetSamplingStatistics                    = "getSamplingStatistics"
+	QueryGetConfiguration                         = "getConfiguration"
+	QueryGetTransactionSearch                     = "getTransactionSearch"
 
-	QueryListServices               = "listServices"
-	QueryListServiceOperations      = "listServiceOperations"
-	QueryListServiceDependencies    = "listServiceDependencies"
-	QueryListServiceLevelObjectives = "listServiceLevelObjectives"
+	QueryListServices                     = "listServices"
+	QueryListServiceOperations            = "listServiceOperations"
+	QueryListServiceDependencies          = "listServiceDependencies"
+	QueryListServiceDependents            = "listServiceDependents"
+	QueryListServiceLevelObjectives       = "listServiceLevelObjectives"
+	QueryGetServiceLevelObjectiveDetails  = "getServiceLevelObjectiveDetails"
+	QueryGetServiceLevelObjectiveHistory  = "getServiceLevelObjectiveHistory"
+	QueryGetServiceLevelObjectiveBreaches = "getServiceLevelObjectiveBreaches"
+	QueryGetServiceMetrics                = "getServiceMetrics"
+	QueryGetServiceDetails                = "getServiceDetails"
+	QueryGetServiceDependencyGraph        = "getServiceDependencyGraph"
 )
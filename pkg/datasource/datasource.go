@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/xray"
 
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
@@ -19,12 +20,14 @@ import (
 
 type XrayClientFactory = func(ctx context.Context, pluginContext backend.PluginContext, requestSettings RequestSettings) (XrayClient, error)
 type AppSignalsClientFactory = func(ctx context.Context, pluginContext backend.PluginContext, requestSettings RequestSettings) (AppSignalsClient, error)
+type CloudWatchClientFactory = func(ctx context.Context, pluginContext backend.PluginContext, requestSettings RequestSettings) (CloudWatchClient, error)
 
 type Datasource struct {
 	Settings                awsds.AWSDatasourceSettings
 	ResourceMux             backend.CallResourceHandler
 	xrayClientFactory       XrayClientFactory
 	appSignalsClientFactory AppSignalsClientFactory
+	cloudWatchClientFactory CloudWatchClientFactory
 
 	authSettings awsds.AuthSettings
 }
@@ -34,11 +37,11 @@ func NewServerInstance(ctx context.Context, s backend.DataSourceInstanceSettings
 	if err != nil {
 		return nil, err
 	}
-	return NewDatasource(ctx, getXrayClient, getAppSignalsClient, settings), nil
+	return NewDatasource(ctx, getXrayClient, getAppSignalsClient, getCloudWatchClient, settings), nil
 }
 
-func NewDatasource(ctx context.Context, xrayClientFactory XrayClientFactory, appSignalsClientFactory AppSignalsClientFactory, settings awsds.AWSDatasourceSettings) *Datasource {
-	ds := &Datasource{xrayClientFactory: xrayClientFactory, appSignalsClientFactory: appSignalsClientFactory, Settings: settings}
+func NewDatasource(ctx context.Context, xrayClientFactory XrayClientFactory, appSignalsClientFactory AppSignalsClientFactory, cloudWatchClientFactory CloudWatchClientFactory, settings awsds.AWSDatasourceSettings) *Datasource {
+	ds := &Datasource{xrayClientFactory: xrayClientFactory, appSignalsClientFactory: appSignalsClientFactory, cloudWatchClientFactory: cloudWatchClientFactory, Settings: settings}
 
 	// resource handler
 	resMux := http.NewServeMux()
@@ -78,8 +81,22 @@ func (ds *Datasource) QueryData(ctx context.Context, req *backend.QueryDataReque
 				currentRes = ds.ListServiceOperations(ctx, query, req.PluginContext)
 			case QueryListServiceDependencies:
 				currentRes = ds.ListServiceDependencies(ctx, query, req.PluginContext)
+			case QueryListServiceDependents:
+				currentRes = ds.ListServiceDependents(ctx, query, req.PluginContext)
 			case QueryListServiceLevelObjectives:
 				currentRes = ds.ListServiceLevelObjectives(ctx, query, req.PluginContext)
+			case QueryGetServiceLevelObjectiveDetails:
+				currentRes = ds.getSingleServiceLevelObjectiveDetails(ctx, query, req.PluginContext)
+			case QueryGetServiceLevelObjectiveHistory:
+				currentRes = ds.getSingleServiceLevelObjectiveHistory(ctx, query, req.PluginContext)
+			case QueryGetServiceLevelObjectiveBreaches:
+				currentRes = ds.getSingleServiceLevelObjectiveBreaches(ctx, query, req.PluginContext)
+			case QueryGetServiceMetrics:
+				currentRes = ds.getSingleServiceMetrics(ctx, query, req.PluginContext)
+			case QueryGetServiceDetails:
+				currentRes = ds.getSingleServiceDetails(ctx, query, req.PluginContext)
+			case QueryGetServiceDependencyGraph:
+				currentRes = ds.getSingleServiceDependencyGraph(ctx, query, req.PluginContext)
 			default:
 				currentRes.Error = backend.DownstreamError(fmt.Errorf("unknown service query type: %s", model.ServiceQueryType))
 			}
@@ -109,6 +126,26 @@ func (ds *Datasource) QueryData(ctx context.Context, req *backend.QueryDataReque
 				currentRes = ds.getSingleInsight(ctx, query, req.PluginContext)
 			case QueryGetServiceMap:
 				currentRes = ds.getSingleServiceMap(ctx, query, req.PluginContext)
+			case QueryGetHistogram:
+				currentRes = ds.getSingleHistogram(ctx, query, req.PluginContext)
+			case QueryGetAlertingMetric:
+				currentRes = ds.getSingleAlertingMetric(ctx, query, req.PluginContext)
+			case QueryGetTraceRate:
+				currentRes = ds.getSingleTraceRate(ctx, query, req.PluginContext)
+			case QueryGetInsightDetails:
+				currentRes = ds.getSingleInsightDetails(ctx, query, req.PluginContext)
+			case QueryGetInsightAnnotations:
+				currentRes = ds.getSingleInsightAnnotations(ctx, query, req.PluginContext)
+			case QueryGetGroups:
+				currentRes = ds.getSingleGroups(ctx, query, req.PluginContext)
+			case QueryGetSamplingRules:
+				currentRes = ds.getSingleSamplingRules(ctx, query, req.PluginContext)
+			case QueryGetSamplingStatistics:
+				currentRes = ds.getSingleSamplingStatistics(ctx, query, req.PluginContext)
+			case QueryGetConfiguration:
+				currentRes = ds.getSingleConfiguration(ctx, query, req.PluginContext)
+			case QueryGetTransactionSearch:
+				currentRes = ds.getSingleTransactionSearch(ctx, query, req.PluginContext)
 			default:
 				currentRes.Error = backend.DownstreamError(fmt.Errorf("unknown query type: %s", query.QueryType))
 			}
@@ -136,6 +173,10 @@ func (ds *Datasource) getAppSignalsClient(ctx context.Context, pluginContext bac
 	return ds.appSignalsClientFactory(ctx, pluginContext, requestSettings)
 }
 
+func (ds *Datasource) getCloudWatchClient(ctx context.Context, pluginContext backend.PluginContext, requestSettings RequestSettings) (CloudWatchClient, error) {
+	return ds.cloudWatchClientFactory(ctx, pluginContext, requestSettings)
+}
+
 func getXrayClient(ctx context.Context, pluginContext backend.PluginContext, requestSettings RequestSettings) (XrayClient, error) {
 	awsSettings, err := getDsSettings(*pluginContext.DataSourceInstanceSettings)
 	if err != nil {
@@ -172,6 +213,24 @@ func getAppSignalsClient(ctx context.Context, pluginContext backend.PluginContex
 	return appSignalsClient, nil
 }
 
+func getCloudWatchClient(ctx context.Context, pluginContext backend.PluginContext, requestSettings RequestSettings) (CloudWatchClient, error) {
+	awsSettings, err := getDsSettings(*pluginContext.DataSourceInstanceSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	// add region from the request body if it's set, otherwise default region will be used
+	if requestSettings.Region != "" && requestSettings.Region != "default" {
+		awsSettings.Region = requestSettings.Region
+	}
+
+	cloudWatchClient, err := client.CreateCloudWatchClient(ctx, awsSettings, *pluginContext.DataSourceInstanceSettings)
+	if err != nil {
+		return nil, err
+	}
+	return cloudWatchClient, nil
+}
+
 type XrayClient interface {
 	xray.BatchGetTracesAPIClient
 	xray.GetInsightSummariesAPIClient
@@ -180,11 +239,30 @@ type XrayClient interface {
 	xray.GetTraceGraphAPIClient
 	xray.GetTraceSummariesAPIClient
 	xray.GetTimeSeriesServiceStatisticsAPIClient
+	xray.GetInsightEventsAPIClient
+	xray.GetSamplingRulesAPIClient
+	xray.GetSamplingStatisticSummariesAPIClient
+	GetInsight(ctx context.Context, params *xray.GetInsightInput, optFns ...func(*xray.Options)) (*xray.GetInsightOutput, error)
+	GetInsightImpactGraph(ctx context.Context, params *xray.GetInsightImpactGraphInput, optFns ...func(*xray.Options)) (*xray.GetInsightImpactGraphOutput, error)
+	GetEncryptionConfig(ctx context.Context, params *xray.GetEncryptionConfigInput, optFns ...func(*xray.Options)) (*xray.GetEncryptionConfigOutput, error)
+	GetIndexingRules(ctx context.Context, params *xray.GetIndexingRulesInput, optFns ...func(*xray.Options)) (*xray.GetIndexingRulesOutput, error)
+	GetTraceSegmentDestination(ctx context.Context, params *xray.GetTraceSegmentDestinationInput, optFns ...func(*xray.Options)) (*xray.GetTraceSegmentDestinationOutput, error)
+	StartTraceRetrieval(ctx context.Context, params *xray.StartTraceRetrievalInput, optFns ...func(*xray.Options)) (*xray.StartTraceRetrievalOutput, error)
+	ListRetrievedTraces(ctx context.Context, params *xray.ListRetrievedTracesInput, optFns ...func(*xray.Options)) (*xray.ListRetrievedTracesOutput, error)
+	GetRetrievedTracesGraph(ctx context.Context, params *xray.GetRetrievedTracesGraphInput, optFns ...func(*xray.Options)) (*xray.GetRetrievedTracesGraphOutput, error)
 }
 
 type AppSignalsClient interface {
 	applicationsignals.ListServicesAPIClient
 	applicationsignals.ListServiceOperationsAPIClient
 	applicationsignals.ListServiceDependenciesAPIClient
+	applicationsignals.ListServiceDependentsAPIClient
 	applicationsignals.ListServiceLevelObjectivesAPIClient
+	BatchGetServiceLevelObjectiveBudgetReport(ctx context.Context, params *applicationsignals.BatchGetServiceLevelObjectiveBudgetReportInput, optFns ...func(*applicationsignals.Options)) (*applicationsignals.BatchGetServiceLevelObjectiveBudgetReportOutput, error)
+	GetServiceLevelObjective(ctx context.Context, params *applicationsignals.GetServiceLevelObjectiveInput, optFns ...func(*applicationsignals.Options)) (*applicationsignals.GetServiceLevelObjectiveOutput, error)
+	GetService(ctx context.Context, params *applicationsignals.GetServiceInput, optFns ...func(*applicationsignals.Options)) (*applicationsignals.GetServiceOutput, error)
+}
+
+type CloudWatchClient interface {
+	cloudwatch.GetMetricDataAPIClient
 }
@@ -3,6 +3,7 @@ package datasource_test
 import (
 	"context"
 	"encoding/json"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
 	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudWatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/xray"
 	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
 	"github.com/grafana/grafana-aws-sdk/pkg/awsds"
@@ -32,12 +35,42 @@ func (client *XrayClientMock) GetServiceGraph(_ context.Context, _ *xray.GetServ
 		NextToken: nil,
 		Services: []xraytypes.Service{
 			{
-				Name:      aws.String(serviceName),
-				AccountId: aws.String("testAccount1"),
+				Name:        aws.String(serviceName),
+				AccountId:   aws.String("testAccount1"),
+				ReferenceId: aws.Int32(0),
+				Type:        aws.String("AWS::EC2::Instance"),
+				StartTime:   aws.Time(time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)),
+				EndTime:     aws.Time(time.Date(2020, 6, 20, 1, 10, 0, 0, time.UTC)),
+				SummaryStatistics: &xraytypes.ServiceStatistics{
+					ErrorStatistics:   &xraytypes.ErrorStatistics{ThrottleCount: aws.Int64(10), TotalCount: aws.Int64(20)},
+					FaultStatistics:   &xraytypes.FaultStatistics{TotalCount: aws.Int64(20)},
+					OkCount:           aws.Int64(60),
+					TotalCount:        aws.Int64(100),
+					TotalResponseTime: aws.Float64(5),
+				},
+				ResponseTimeHistogram: []xraytypes.HistogramEntry{{Count: 60, Value: 0.05}, {Count: 40, Value: 0.01}},
+				DurationHistogram:     []xraytypes.HistogramEntry{{Count: 100, Value: 0.06}},
+				Edges: []xraytypes.Edge{
+					{
+						ReferenceId:           aws.Int32(1),
+						ResponseTimeHistogram: []xraytypes.HistogramEntry{{Count: 50, Value: 0.02}},
+						StartTime:             aws.Time(time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)),
+						EndTime:               aws.Time(time.Date(2020, 6, 20, 1, 10, 0, 0, time.UTC)),
+						SummaryStatistics: &xraytypes.EdgeStatistics{
+							OkCount:           aws.Int64(50),
+							TotalCount:        aws.Int64(50),
+							TotalResponseTime: aws.Float64(1),
+						},
+					},
+					// Edge to a service that is not part of the response should be ignored
+					{ReferenceId: aws.Int32(5)},
+				},
 			},
 			{
-				Name:      aws.String(serviceName + "2"),
-				AccountId: aws.String("testAccount2"),
+				Name:        aws.String(serviceName + "2"),
+				AccountId:   aws.String("testAccount2"),
+				ReferenceId: aws.Int32(1),
+				Type:        aws.String("AWS::DynamoDB::Table"),
 			},
 		},
 	}, nil
@@ -150,7 +183,10 @@ func makeSummary(region string) xraytypes.TraceSummary {
 	}
 }
 
-func (client *XrayClientMock) GetTraceSummaries(_ context.Context, _ *xray.GetTraceSummariesInput, _ ...func(*xray.Options)) (*xray.GetTraceSummariesOutput, error) {
+// Filter expression of the mock group. Only the first trace summary is in the group.
+const mockGroupFilterExpression = `service("mockServiceName")`
+
+func (client *XrayClientMock) GetTraceSummaries(_ context.Context, input *xray.GetTraceSummariesInput, _ ...func(*xray.Options)) (*xray.GetTraceSummariesOutput, error) {
 	// To make sure we don't panic in this case.
 	nilHttpSummary := makeSummary(client.queryCalledWithRegion)
 	nilHttpSummary.Http.ClientIp = nil
@@ -162,6 +198,9 @@ func (client *XrayClientMock) GetTraceSummaries(_ context.Context, _ *xray.GetTr
 		ApproximateTime: aws.Time(time.Now()),
 		TraceSummaries:  []xraytypes.TraceSummary{makeSummary(client.queryCalledWithRegion), nilHttpSummary},
 	}
+	if input.FilterExpression != nil && strings.HasPrefix(*input.FilterExpression, "("+mockGroupFilterExpression+") AND ") {
+		output.TraceSummaries = output.TraceSummaries[:1]
+	}
 
 	return output, nil
 }
@@ -190,17 +229,22 @@ func (client *XrayClientMock) BatchGetTraces(_ context.Context, input *xray.Batc
 	}, nil
 }
 
-func (client *XrayClientMock) GetTimeSeriesServiceStatistics(_ context.Context, _ *xray.GetTimeSeriesServiceStatisticsInput, _ ...func(*xray.Options)) (*xray.GetTimeSeriesServiceStatisticsOutput, error) {
+func (client *XrayClientMock) GetTimeSeriesServiceStatistics(_ context.Context, input *xray.GetTimeSeriesServiceStatisticsInput, _ ...func(*xray.Options)) (*xray.GetTimeSeriesServiceStatisticsOutput, error) {
 	firstRow := 0
 	if client.queryCalledWithRegion != "" {
 		firstRow = 13
 	}
 
+	serviceRow := makeTimeSeriesRow(2, Service)
+	if input.ForecastStatistics != nil && *input.ForecastStatistics {
+		serviceRow.ServiceForecastStatistics = &xraytypes.ForecastStatistics{FaultCountHigh: aws.Int64(30), FaultCountLow: aws.Int64(5)}
+	}
+
 	output := &xray.GetTimeSeriesServiceStatisticsOutput{
 		TimeSeriesServiceStatistics: []xraytypes.TimeSeriesServiceStatistics{
 			makeTimeSeriesRow(firstRow, Edge),
 			makeTimeSeriesRow(1, Edge),
-			makeTimeSeriesRow(2, Service),
+			serviceRow,
 		},
 	}
 	return output, nil
@@ -208,8 +252,24 @@ func (client *XrayClientMock) GetTimeSeriesServiceStatistics(_ context.Context,
 
 const insightSummary = "some text. some more."
 
-func (client *XrayClientMock) GetInsightSummaries(_ context.Context, _ *xray.GetInsightSummariesInput, _ ...func(*xray.Options)) (*xray.GetInsightSummariesOutput, error) {
+func (client *XrayClientMock) GetInsightSummaries(_ context.Context, input *xray.GetInsightSummariesInput, _ ...func(*xray.Options)) (*xray.GetInsightSummariesOutput, error) {
+	// Second page has an insight which is still being analyzed and misses most of the fields
+	if input.NextToken != nil {
+		return &xray.GetInsightSummariesOutput{
+			InsightSummaries: []xraytypes.InsightSummary{
+				{
+					Summary:   aws.String("no sentences"),
+					StartTime: aws.Time(time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC)),
+					EndTime:   aws.Time(time.Date(2020, 6, 20, 1, 20, 1, 0, time.UTC)),
+					State:     xraytypes.InsightStateClosed,
+					GroupName: aws.String("Grafana"),
+					InsightId: aws.String("id-3-" + client.queryCalledWithRegion),
+				},
+			},
+		}, nil
+	}
 	return &xray.GetInsightSummariesOutput{
+		NextToken: aws.String("next"),
 		InsightSummaries: []xraytypes.InsightSummary{
 			{
 				Summary:              aws.String(insightSummary),
@@ -237,6 +297,166 @@ func (client *XrayClientMock) GetInsightSummaries(_ context.Context, _ *xray.Get
 	}, nil
 }
 
+func (client *XrayClientMock) GetInsight(_ context.Context, input *xray.GetInsightInput, _ ...func(*xray.Options)) (*xray.GetInsightOutput, error) {
+	if *input.InsightId == "notFound" {
+		return &xray.GetInsightOutput{}, nil
+	}
+	return &xray.GetInsightOutput{
+		Insight: &xraytypes.Insight{
+			InsightId:                               input.InsightId,
+			Summary:                                 aws.String(insightSummary),
+			StartTime:                               aws.Time(time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC)),
+			EndTime:                                 aws.Time(time.Date(2020, 6, 20, 1, 20, 1, 0, time.UTC)),
+			State:                                   xraytypes.InsightStateClosed,
+			GroupName:                               aws.String("Grafana"),
+			RootCauseServiceId:                      &xraytypes.ServiceId{Name: aws.String("graf"), Type: aws.String("AWS")},
+			TopAnomalousServices:                    []xraytypes.AnomalousService{{ServiceId: &xraytypes.ServiceId{Name: aws.String("graf2"), Type: aws.String("AWS2")}}},
+			ClientRequestImpactStatistics:           &xraytypes.RequestImpactStatistics{FaultCount: aws.Int64(10), OkCount: aws.Int64(90), TotalCount: aws.Int64(100)},
+			RootCauseServiceRequestImpactStatistics: &xraytypes.RequestImpactStatistics{FaultCount: aws.Int64(20), OkCount: aws.Int64(180), TotalCount: aws.Int64(200)},
+		},
+	}, nil
+}
+
+func (client *XrayClientMock) GetInsightEvents(_ context.Context, input *xray.GetInsightEventsInput, _ ...func(*xray.Options)) (*xray.GetInsightEventsOutput, error) {
+	// Events on two pages and out of order
+	if input.NextToken == nil {
+		return &xray.GetInsightEventsOutput{
+			InsightEvents: []xraytypes.InsightEvent{
+				{
+					EventTime:                     aws.Time(time.Date(2020, 6, 20, 1, 10, 1, 0, time.UTC)),
+					Summary:                       aws.String("Second event"),
+					ClientRequestImpactStatistics: &xraytypes.RequestImpactStatistics{FaultCount: aws.Int64(5), OkCount: aws.Int64(45), TotalCount: aws.Int64(50)},
+				},
+			},
+			NextToken: aws.String("next"),
+		}, nil
+	}
+	return &xray.GetInsightEventsOutput{
+		InsightEvents: []xraytypes.InsightEvent{
+			{
+				EventTime:            aws.Time(time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC)),
+				Summary:              aws.String("First event"),
+				TopAnomalousServices: []xraytypes.AnomalousService{{ServiceId: &xraytypes.ServiceId{Name: aws.String("graf2"), Type: aws.String("AWS2")}}},
+			},
+		},
+	}, nil
+}
+
+func (client *XrayClientMock) GetInsightImpactGraph(_ context.Context, _ *xray.GetInsightImpactGraphInput, _ ...func(*xray.Options)) (*xray.GetInsightImpactGraphOutput, error) {
+	return &xray.GetInsightImpactGraphOutput{
+		Services: []xraytypes.InsightImpactGraphService{
+			{ReferenceId: aws.Int32(0), Name: aws.String("graf"), Type: aws.String("AWS"), Edges: []xraytypes.InsightImpactGraphEdge{{ReferenceId: aws.Int32(1)}}},
+			{ReferenceId: aws.Int32(1), Name: aws.String("graf2"), Type: aws.String("AWS2"), Edges: []xraytypes.InsightImpactGraphEdge{{ReferenceId: aws.Int32(2)}, {ReferenceId: aws.Int32(5)}}},
+			{ReferenceId: aws.Int32(2), Name: aws.String("client"), Type: aws.String("client")},
+		},
+	}, nil
+}
+
+func (client *XrayClientMock) GetSamplingRules(_ context.Context, _ *xray.GetSamplingRulesInput, _ ...func(*xray.Options)) (*xray.GetSamplingRulesOutput, error) {
+	return &xray.GetSamplingRulesOutput{
+		SamplingRuleRecords: []xraytypes.SamplingRuleRecord{
+			{
+				SamplingRule: &xraytypes.SamplingRule{
+					RuleName:      aws.String("Default"),
+					Priority:      aws.Int32(10000),
+					FixedRate:     0.05,
+					ReservoirSize: 1,
+					ServiceName:   aws.String("*"),
+				},
+			},
+			{
+				SamplingRule: &xraytypes.SamplingRule{
+					RuleName:      aws.String("Checkout"),
+					Priority:      aws.Int32(1),
+					FixedRate:     1,
+					ReservoirSize: 10,
+					ServiceName:   aws.String("checkout"),
+					URLPath:       aws.String("/pay/*"),
+					Attributes:    map[string]string{"tier": "gold"},
+				},
+			},
+		},
+	}, nil
+}
+
+func (client *XrayClientMock) GetSamplingStatisticSummaries(_ context.Context, _ *xray.GetSamplingStatisticSummariesInput, _ ...func(*xray.Options)) (*xray.GetSamplingStatisticSummariesOutput, error) {
+	return &xray.GetSamplingStatisticSummariesOutput{
+		SamplingStatisticSummaries: []xraytypes.SamplingStatisticSummary{
+			{RuleName: aws.String("Default"), Timestamp: aws.Time(time.Date(2020, 6, 20, 1, 10, 0, 0, time.UTC)), RequestCount: 100, SampledCount: 6, BorrowCount: 1},
+			{RuleName: aws.String("Default"), Timestamp: aws.Time(time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)), RequestCount: 200, SampledCount: 11, BorrowCount: 0},
+			{RuleName: aws.String("Checkout"), Timestamp: aws.Time(time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)), RequestCount: 20, SampledCount: 20, BorrowCount: 0},
+			// Outside of the time range
+			{RuleName: aws.String("Default"), Timestamp: aws.Time(time.Date(2020, 6, 19, 1, 0, 0, 0, time.UTC)), RequestCount: 1},
+		},
+	}, nil
+}
+
+// Only us-east-1 is configured with KMS encryption and CloudWatch Logs destination
+func (client *XrayClientMock) GetEncryptionConfig(_ context.Context, _ *xray.GetEncryptionConfigInput, _ ...func(*xray.Options)) (*xray.GetEncryptionConfigOutput, error) {
+	if client.queryCalledWithRegion == "us-east-1" {
+		return &xray.GetEncryptionConfigOutput{
+			EncryptionConfig: &xraytypes.EncryptionConfig{Type: xraytypes.EncryptionTypeKms, Status: xraytypes.EncryptionStatusActive, KeyId: aws.String("alias/xray")},
+		}, nil
+	}
+	return &xray.GetEncryptionConfigOutput{
+		EncryptionConfig: &xraytypes.EncryptionConfig{Type: xraytypes.EncryptionTypeNone, Status: xraytypes.EncryptionStatusActive},
+	}, nil
+}
+
+func (client *XrayClientMock) GetIndexingRules(_ context.Context, _ *xray.GetIndexingRulesInput, _ ...func(*xray.Options)) (*xray.GetIndexingRulesOutput, error) {
+	if client.queryCalledWithRegion == "us-east-1" {
+		return &xray.GetIndexingRulesOutput{
+			IndexingRules: []xraytypes.IndexingRule{
+				{
+					Name: aws.String("Default"),
+					Rule: &xraytypes.IndexingRuleValueMemberProbabilistic{
+						Value: xraytypes.ProbabilisticRuleValue{DesiredSamplingPercentage: aws.Float64(1), ActualSamplingPercentage: aws.Float64(0.9)},
+					},
+				},
+			},
+		}, nil
+	}
+	return &xray.GetIndexingRulesOutput{}, nil
+}
+
+func (client *XrayClientMock) GetTraceSegmentDestination(_ context.Context, _ *xray.GetTraceSegmentDestinationInput, _ ...func(*xray.Options)) (*xray.GetTraceSegmentDestinationOutput, error) {
+	if client.queryCalledWithRegion == "us-east-1" {
+		return &xray.GetTraceSegmentDestinationOutput{Destination: xraytypes.TraceSegmentDestinationCloudWatchLogs, Status: xraytypes.TraceSegmentDestinationStatusActive}, nil
+	}
+	return &xray.GetTraceSegmentDestinationOutput{Destination: xraytypes.TraceSegmentDestinationXRay, Status: xraytypes.TraceSegmentDestinationStatusActive}, nil
+}
+
+func (client *XrayClientMock) StartTraceRetrieval(_ context.Context, input *xray.StartTraceRetrievalInput, _ ...func(*xray.Options)) (*xray.StartTraceRetrievalOutput, error) {
+	return &xray.StartTraceRetrievalOutput{RetrievalToken: aws.String(strings.Join(input.TraceIds, ","))}, nil
+}
+
+func (client *XrayClientMock) ListRetrievedTraces(_ context.Context, input *xray.ListRetrievedTracesInput, _ ...func(*xray.Options)) (*xray.ListRetrievedTracesOutput, error) {
+	if client.queryCalledWithRegion == "failing-retrieval" {
+		return &xray.ListRetrievedTracesOutput{RetrievalStatus: xraytypes.RetrievalStatusFailed}, nil
+	}
+	traces := []xraytypes.RetrievedTrace{}
+	for _, traceId := range strings.Split(*input.RetrievalToken, ",") {
+		traces = append(traces, xraytypes.RetrievedTrace{
+			Id:       aws.String(traceId),
+			Duration: aws.Float64(1.5),
+			Spans: []xraytypes.Span{
+				{Id: aws.String("span1"), Document: aws.String(`{"id":"span1","name":"frontend"}`)},
+				{Id: aws.String("span2"), Document: aws.String(`{"id":"span2","name":"backend","parent_id":"span1"}`)},
+			},
+		})
+	}
+	return &xray.ListRetrievedTracesOutput{RetrievalStatus: xraytypes.RetrievalStatusComplete, TraceFormat: input.TraceFormat, Traces: traces}, nil
+}
+
+func (client *XrayClientMock) GetRetrievedTracesGraph(_ context.Context, _ *xray.GetRetrievedTracesGraphInput, _ ...func(*xray.Options)) (*xray.GetRetrievedTracesGraphOutput, error) {
+	return &xray.GetRetrievedTracesGraphOutput{
+		RetrievalStatus: xraytypes.RetrievalStatusComplete,
+		Services: []xraytypes.RetrievedService{
+			{Service: &xraytypes.Service{Name: aws.String("frontend"), ReferenceId: aws.Int32(0)}},
+		},
+	}, nil
+}
+
 func (client *XrayClientMock) GetGroups(_ context.Context, _ *xray.GetGroupsInput, _ ...func(*xray.Options)) (*xray.GetGroupsOutput, error) {
 	return &xray.GetGroupsOutput{
 		Groups: []xraytypes.GroupSummary{
@@ -249,6 +469,10 @@ func (client *XrayClientMock) GetGroups(_ context.Context, _ *xray.GetGroupsInpu
 				GroupARN:         aws.String("arn:2"),
 				GroupName:        aws.String("GroupTest"),
 				FilterExpression: aws.String("service(\"test\")"),
+				InsightsConfiguration: &xraytypes.InsightsConfiguration{
+					InsightsEnabled:      aws.Bool(true),
+					NotificationsEnabled: aws.Bool(false),
+				},
 			},
 		},
 	}, nil
@@ -462,29 +686,167 @@ func (client *AppSignalsClientMock) ListServiceDependencies(context.Context, *ap
 	}, nil
 }
 
-func (client *AppSignalsClientMock) ListServiceLevelObjectives(context.Context, *applicationsignals.ListServiceLevelObjectivesInput, ...func(*applicationsignals.Options)) (*applicationsignals.ListServiceLevelObjectivesOutput, error) {
-	return &applicationsignals.ListServiceLevelObjectivesOutput{
-		SloSummaries: []appSignalsTypes.ServiceLevelObjectiveSummary{
+// ListServiceDependents returns the frontend calling every service except the frontend itself.
+func (client *AppSignalsClientMock) ListServiceDependents(_ context.Context, input *applicationsignals.ListServiceDependentsInput, _ ...func(*applicationsignals.Options)) (*applicationsignals.ListServiceDependentsOutput, error) {
+	if input.KeyAttributes["Name"] == "pet-clinic-frontend-java" {
+		return &applicationsignals.ListServiceDependentsOutput{}, nil
+	}
+	return &applicationsignals.ListServiceDependentsOutput{
+		ServiceDependents: []appSignalsTypes.ServiceDependent{
 			{
-				Name:          aws.String("testSLO"),
-				OperationName: aws.String("GET /**"),
-				CreatedTime:   aws.Time(time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)),
-				KeyAttributes: map[string]string{
-					"Environment":  "eks:app-signals-demo/default",
-					"Name":         "vets-service-java",
-					"AwsAccountId": "000000000000",
+				OperationName:          aws.String("InternalOperation"),
+				DependentOperationName: aws.String("GET /api/billing"),
+				DependentKeyAttributes: map[string]string{
+					"Environment": "eks:app-signals-demo/default",
+					"Name":        "pet-clinic-frontend-java",
+					"Type":        "Service",
+				},
+				MetricReferences: []appSignalsTypes.MetricReference{
+					{
+						MetricName: aws.String("Latency"),
+						MetricType: aws.String("LATENCY"),
+						Namespace:  aws.String("AppSignals"),
+						Dimensions: []appSignalsTypes.Dimension{
+							{Name: aws.String("Service"), Value: aws.String("pet-clinic-frontend-java")},
+						},
+					},
 				},
 			},
-			{
-				Name:          aws.String("Latency for Frontend InternalOperationInternalOperation"),
-				OperationName: aws.String("InternalOperation"),
-				CreatedTime:   aws.Time(time.Date(2025, time.February, 1, 12, 0, 0, 0, time.UTC)),
-				KeyAttributes: map[string]string{
-					"Type":         "Service",
-					"Name":         "pet-clinic-frontend-java",
-					"AwsAccountId": "999999999999",
+		},
+	}, nil
+}
+
+func (client *AppSignalsClientMock) ListServiceLevelObjectives(_ context.Context, input *applicationsignals.ListServiceLevelObjectivesInput, _ ...func(*applicationsignals.Options)) (*applicationsignals.ListServiceLevelObjectivesOutput, error) {
+	sloSummaries := []appSignalsTypes.ServiceLevelObjectiveSummary{
+		{
+			Name:             aws.String("testSLO"),
+			OperationName:    aws.String("GET /**"),
+			CreatedTime:      aws.Time(time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)),
+			MetricSourceType: appSignalsTypes.MetricSourceTypeServiceOperation,
+			EvaluationType:   appSignalsTypes.EvaluationTypePeriodBased,
+			KeyAttributes: map[string]string{
+				"Environment":  "eks:app-signals-demo/default",
+				"Name":         "vets-service-java",
+				"AwsAccountId": "000000000000",
+			},
+		},
+		{
+			Name:             aws.String("Latency for Frontend InternalOperationInternalOperation"),
+			OperationName:    aws.String("InternalOperation"),
+			CreatedTime:      aws.Time(time.Date(2025, time.February, 1, 12, 0, 0, 0, time.UTC)),
+			MetricSourceType: appSignalsTypes.MetricSourceTypeServiceDependency,
+			EvaluationType:   appSignalsTypes.EvaluationTypeRequestBased,
+			KeyAttributes: map[string]string{
+				"Type":         "Service",
+				"Name":         "pet-clinic-frontend-java",
+				"AwsAccountId": "999999999999",
+			},
+			DependencyConfig: &appSignalsTypes.DependencyConfig{
+				DependencyKeyAttributes: map[string]string{"Type": "AWS::Resource", "ResourceType": "AWS::DynamoDB::Table"},
+				DependencyOperationName: aws.String("PutItem"),
+			},
+		},
+	}
+
+	output := &applicationsignals.ListServiceLevelObjectivesOutput{}
+	for _, sloSummary := range sloSummaries {
+		if len(input.MetricSourceTypes) > 0 && !slices.Contains(input.MetricSourceTypes, sloSummary.MetricSourceType) {
+			continue
+		}
+		output.SloSummaries = append(output.SloSummaries, sloSummary)
+	}
+	return output, nil
+}
+
+var sloBreachStart = time.Date(2024, time.January, 1, 12, 30, 0, 0, time.UTC)
+
+func (client *AppSignalsClientMock) BatchGetServiceLevelObjectiveBudgetReport(_ context.Context, input *applicationsignals.BatchGetServiceLevelObjectiveBudgetReportInput, _ ...func(*applicationsignals.Options)) (*applicationsignals.BatchGetServiceLevelObjectiveBudgetReportOutput, error) {
+	output := &applicationsignals.BatchGetServiceLevelObjectiveBudgetReportOutput{Timestamp: input.Timestamp}
+	for i, id := range input.SloIds {
+		report := appSignalsTypes.ServiceLevelObjectiveBudgetReport{Name: aws.String(id)}
+		// Only the first SLO has data
+		if i == 0 {
+			report.Attainment = aws.Float64(99.5)
+			report.EvaluationType = appSignalsTypes.EvaluationTypePeriodBased
+			report.TotalBudgetSeconds = aws.Int32(1000)
+			report.BudgetSecondsRemaining = aws.Int32(250)
+			report.BudgetStatus = appSignalsTypes.ServiceLevelObjectiveBudgetStatusWarning
+			// The budget runs out for ten minutes
+			if !input.Timestamp.Before(sloBreachStart) && !input.Timestamp.After(sloBreachStart.Add(10*time.Minute)) {
+				report.Attainment = aws.Float64(99)
+				report.BudgetSecondsRemaining = aws.Int32(0)
+				report.BudgetStatus = appSignalsTypes.ServiceLevelObjectiveBudgetStatusBreached
+			}
+		}
+		output.Reports = append(output.Reports, report)
+	}
+	return output, nil
+}
+
+func (client *AppSignalsClientMock) GetServiceLevelObjective(_ context.Context, input *applicationsignals.GetServiceLevelObjectiveInput, _ ...func(*applicationsignals.Options)) (*applicationsignals.GetServiceLevelObjectiveOutput, error) {
+	if *input.Id == "testSLO" {
+		return &applicationsignals.GetServiceLevelObjectiveOutput{
+			Slo: &appSignalsTypes.ServiceLevelObjective{
+				Name:           input.Id,
+				Description:    aws.String("Latency of vets service"),
+				EvaluationType: appSignalsTypes.EvaluationTypePeriodBased,
+				Goal: &appSignalsTypes.Goal{
+					AttainmentGoal:   aws.Float64(99.9),
+					WarningThreshold: aws.Float64(30),
+					Interval:         &appSignalsTypes.IntervalMemberRollingInterval{Value: appSignalsTypes.RollingInterval{Duration: aws.Int32(7), DurationUnit: appSignalsTypes.DurationUnitDay}},
+				},
+				Sli: &appSignalsTypes.ServiceLevelIndicator{
+					SliMetric:          &appSignalsTypes.ServiceLevelIndicatorMetric{OperationName: aws.String("GET /**"), MetricType: "LATENCY"},
+					MetricThreshold:    aws.Float64(200),
+					ComparisonOperator: "LessThan",
 				},
 			},
+		}, nil
+	}
+	return &applicationsignals.GetServiceLevelObjectiveOutput{
+		Slo: &appSignalsTypes.ServiceLevelObjective{
+			Name:           input.Id,
+			EvaluationType: appSignalsTypes.EvaluationTypeRequestBased,
+			Goal: &appSignalsTypes.Goal{
+				AttainmentGoal: aws.Float64(99),
+				Interval:       &appSignalsTypes.IntervalMemberCalendarInterval{Value: appSignalsTypes.CalendarInterval{Duration: aws.Int32(1), DurationUnit: appSignalsTypes.DurationUnitMonth}},
+			},
+			RequestBasedSli: &appSignalsTypes.RequestBasedServiceLevelIndicator{
+				RequestBasedSliMetric: &appSignalsTypes.RequestBasedServiceLevelIndicatorMetric{OperationName: aws.String("InternalOperation"), MetricType: "AVAILABILITY"},
+			},
+		},
+	}, nil
+}
+
+func (client *AppSignalsClientMock) GetService(context.Context, *applicationsignals.GetServiceInput, ...func(*applicationsignals.Options)) (*applicationsignals.GetServiceOutput, error) {
+	return &applicationsignals.GetServiceOutput{
+		Service: &appSignalsTypes.Service{
+			KeyAttributes: map[string]string{"Environment": "eks:app-signals-demo/default", "Name": "billing-service-python", "Type": "Service"},
+			AttributeMaps: []map[string]string{
+				{"PlatformType": "AWS::EKS", "EKS.Cluster": "app-signals-demo", "K8s.Namespace": "default"},
+				{"Telemetry.SDK": "sdk", "Telemetry.Agent": "agent"},
+			},
+			MetricReferences: []appSignalsTypes.MetricReference{
+				{
+					MetricName: aws.String("Latency"),
+					MetricType: aws.String("LATENCY"),
+					Namespace:  aws.String("ApplicationSignals"),
+					Dimensions: []appSignalsTypes.Dimension{{Name: aws.String("Service"), Value: aws.String("billing-service-python")}},
+				},
+				{
+					MetricName: aws.String("Fault"),
+					MetricType: aws.String("FAULT"),
+					Namespace:  aws.String("ApplicationSignals"),
+					AccountId:  aws.String("569069006612"),
+				},
+			},
+			LogGroupReferences: []map[string]string{
+				{"Identifier": "/aws/containerinsights/app-signals-demo/application", "ResourceType": "AWS::Logs::LogGroup", "Type": "AWS::Resource"},
+			},
+		},
+		LogGroupReferences: []map[string]string{
+			{"Identifier": "/aws/application-signals/data", "ResourceType": "AWS::Logs::LogGroup", "Type": "AWS::Resource", "AwsAccountId": "569069006612"},
+			{"Identifier": "/aws/containerinsights/app-signals-demo/application", "ResourceType": "AWS::Logs::LogGroup", "Type": "AWS::Resource"},
 		},
 	}, nil
 }
@@ -495,6 +857,35 @@ func appSignalsClientFactory(_ context.Context, _ backend.PluginContext, request
 	}, nil
 }
 
+type CloudWatchClientMock struct{}
+
+// GetMetricData returns three points for each metric, the first one on the first page and the others on the second one.
+// The values are the number of the metric in the request.
+func (client *CloudWatchClientMock) GetMetricData(_ context.Context, input *cloudwatch.GetMetricDataInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	output := &cloudwatch.GetMetricDataOutput{}
+	for i, query := range input.MetricDataQueries {
+		period := time.Duration(*query.MetricStat.Period) * time.Second
+		result := cloudWatchTypes.MetricDataResult{Id: query.Id, StatusCode: cloudWatchTypes.StatusCodeComplete}
+		points := []int{0}
+		if input.NextToken != nil {
+			points = []int{1, 2}
+		}
+		for _, point := range points {
+			result.Timestamps = append(result.Timestamps, input.StartTime.Add(time.Duration(point)*period))
+			result.Values = append(result.Values, float64(i+1))
+		}
+		output.MetricDataResults = append(output.MetricDataResults, result)
+	}
+	if input.NextToken == nil {
+		output.NextToken = aws.String("next")
+	}
+	return output, nil
+}
+
+func cloudWatchClientFactory(_ context.Context, _ backend.PluginContext, _ datasource.RequestSettings) (datasource.CloudWatchClient, error) {
+	return &CloudWatchClientMock{}, nil
+}
+
 func queryDatasource(ds *datasource.Datasource, queryType string, query interface{}) (*backend.QueryDataResponse, error) {
 	jsonData, _ := json.Marshal(query)
 
@@ -527,7 +918,7 @@ func queryDatasourceResource(ds *datasource.Datasource, req *backend.CallResourc
 
 func TestDatasource(t *testing.T) {
 	settings := awsds.AWSDatasourceSettings{}
-	ds := datasource.NewDatasource(context.Background(), xrayClientFactory, appSignalsClientFactory, settings)
+	ds := datasource.NewDatasource(context.Background(), xrayClientFactory, appSignalsClientFactory, cloudWatchClientFactory, settings)
 
 	t.Run("getInsightSummaries query", func(t *testing.T) {
 		// Insight with nil EndTime should not throw error
@@ -553,8 +944,14 @@ func TestDatasource(t *testing.T) {
 		// RootCauseServiceId should be Name (Type)
 		require.Equal(t, "graf (AWS)", response.Responses["A"].Frames[0].Fields[5].At(0))
 
-		// TopAnomalousServices should be Name (Type)
-		require.Equal(t, "graf2 (AWS2)", response.Responses["A"].Frames[0].Fields[6].At(0))
+		// TopAnomalousServices should be a list of Name (Type)
+		require.JSONEq(t, `["graf2 (AWS2)"]`, string(response.Responses["A"].Frames[0].Fields[6].At(0).(json.RawMessage)))
+
+		// Insight from the second page without root cause, anomalous services and categories
+		require.Equal(t, 3, response.Responses["A"].Frames[0].Rows())
+		require.Equal(t, "no sentences", response.Responses["A"].Frames[0].Fields[1].At(2))
+		require.Equal(t, "", response.Responses["A"].Frames[0].Fields[5].At(2))
+		require.JSONEq(t, `[]`, string(response.Responses["A"].Frames[0].Fields[6].At(2).(json.RawMessage)))
 	})
 
 	t.Run("getInsightSummaries query with different region", func(t *testing.T) {
@@ -566,6 +963,155 @@ func TestDatasource(t *testing.T) {
 		require.Equal(t, "id-us-east-1", *frame.Fields[0].At(0).(*string))
 	})
 
+	t.Run("getInsightAnnotations query", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetInsightAnnotations, datasource.GetInsightsQueryData{State: "All", Group: &xraytypes.Group{GroupName: aws.String("Grafana")}})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, time.Date(2020, 6, 20, 1, 20, 1, 0, time.UTC), *frame.Fields[1].At(0).(*time.Time))
+		require.Equal(t, "X-Ray insight in graf (AWS)", frame.Fields[2].At(0))
+		require.Equal(t, "some more.", frame.Fields[3].At(0))
+		require.Equal(t, "Fault,Error,Grafana,graf (AWS)", frame.Fields[4].At(0))
+
+		// Active insight ends at the end of the time range
+		require.NotNil(t, frame.Fields[1].At(1))
+
+		// Insight without root cause and categories
+		require.Equal(t, "X-Ray insight", frame.Fields[2].At(2))
+		require.Equal(t, "Grafana", frame.Fields[4].At(2))
+	})
+
+	t.Run("getGroups query", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetGroups, datasource.GetGroupsQueryData{})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "Default", frame.Fields[0].At(0))
+		require.Equal(t, false, frame.Fields[3].At(0))
+		require.Equal(t, "GroupTest", frame.Fields[0].At(1))
+		require.Equal(t, "arn:2", frame.Fields[1].At(1))
+		require.Equal(t, "service(\"test\")", frame.Fields[2].At(1))
+		require.Equal(t, true, frame.Fields[3].At(1))
+		require.Equal(t, false, frame.Fields[4].At(1))
+		// Total count of the three mocked statistics rows
+		require.Equal(t, int64(240), frame.Fields[5].At(1))
+	})
+
+	t.Run("getSamplingRules query", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetSamplingRules, datasource.GetSamplingQueryData{})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Rules are ordered by priority
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "Checkout", frame.Fields[0].At(0))
+		require.Equal(t, int32(1), frame.Fields[1].At(0))
+		require.Equal(t, float64(1), frame.Fields[2].At(0))
+		require.Equal(t, int32(10), frame.Fields[3].At(0))
+		require.Equal(t, "/pay/*", frame.Fields[8].At(0))
+		require.JSONEq(t, `{"tier":"gold"}`, string(frame.Fields[10].At(0).(json.RawMessage)))
+		require.Equal(t, "Default", frame.Fields[0].At(1))
+	})
+
+	t.Run("getSamplingStatistics query", func(t *testing.T) {
+		jsonData, _ := json.Marshal(datasource.GetSamplingQueryData{})
+		response, err := ds.QueryData(
+			context.Background(),
+			&backend.QueryDataRequest{Queries: []backend.DataQuery{{
+				RefID:     "A",
+				QueryType: datasource.QueryGetSamplingStatistics,
+				JSON:      jsonData,
+				TimeRange: backend.TimeRange{From: time.Date(2020, 6, 20, 0, 0, 0, 0, time.UTC), To: time.Date(2020, 6, 20, 2, 0, 0, 0, time.UTC)},
+			}}},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Sorted by time and rule
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, "Checkout", frame.Fields[1].At(0))
+		require.Equal(t, "Default", frame.Fields[1].At(1))
+		require.Equal(t, int32(200), frame.Fields[2].At(1))
+		require.Equal(t, int32(11), frame.Fields[3].At(1))
+		require.Equal(t, int32(1), frame.Fields[4].At(2))
+	})
+
+	t.Run("getConfiguration query for multiple regions", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetConfiguration, datasource.GetConfigurationQueryData{Regions: []string{"us-east-1", "eu-west-1"}})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+		require.Equal(t, 2, frame.Rows())
+
+		require.Equal(t, float64(1), frame.Fields[1].At(0))
+		require.Equal(t, float64(1), *frame.Fields[2].At(0).(*float64))
+		require.Equal(t, 0.9, *frame.Fields[3].At(0).(*float64))
+		require.Equal(t, float64(1), frame.Fields[4].At(0))
+		require.Equal(t, "us-east-1", frame.Fields[5].At(0))
+		require.Equal(t, "alias/xray", frame.Fields[8].At(0))
+		require.Equal(t, "Default", frame.Fields[9].At(0))
+		require.Equal(t, "CloudWatchLogs", frame.Fields[10].At(0))
+
+		// Region without indexing rules still has a row
+		require.Equal(t, float64(0), frame.Fields[1].At(1))
+		require.Nil(t, frame.Fields[2].At(1))
+		require.Equal(t, float64(0), frame.Fields[4].At(1))
+		require.Equal(t, "eu-west-1", frame.Fields[5].At(1))
+		require.Equal(t, "NONE", frame.Fields[6].At(1))
+		require.Equal(t, "XRay", frame.Fields[10].At(1))
+	})
+
+	t.Run("getConfiguration query for default region", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetConfiguration, datasource.GetConfigurationQueryData{})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "default", frame.Fields[5].At(0))
+	})
+
+	t.Run("getTransactionSearch query", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetTransactionSearch, datasource.GetTransactionSearchQueryData{Query: "service(\"frontend\")"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Both mocked summaries have the same trace id so there is one trace and the graph
+		frames := response.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.Equal(t, "Traces", frames[0].Name)
+		trace := &xraytypes.Trace{}
+		require.NoError(t, json.Unmarshal([]byte(frames[0].Fields[0].At(0).(string)), trace))
+		require.Equal(t, "id1", *trace.Id)
+		require.Len(t, trace.Segments, 2)
+		require.Equal(t, `{"id":"span1","name":"frontend"}`, *trace.Segments[0].Document)
+
+		require.Equal(t, "TraceGraph", frames[1].Name)
+		require.Equal(t, 1, frames[1].Rows())
+	})
+
+	t.Run("getTransactionSearch query with a limit over the maximum", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetTransactionSearch, datasource.GetTransactionSearchQueryData{Limit: 6})
+		require.NoError(t, err)
+		require.ErrorContains(t, response.Responses["A"].Error, "limit can be at most 5")
+	})
+
+	t.Run("getTransactionSearch query with failed retrieval", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetTransactionSearch, datasource.GetTransactionSearchQueryData{Region: "failing-retrieval"})
+		require.NoError(t, err)
+		require.ErrorContains(t, response.Responses["A"].Error, "FAILED")
+	})
+
 	t.Run("getTrace query", func(t *testing.T) {
 		response, err := queryDatasource(ds, datasource.QueryGetTrace, datasource.GetTraceQueryData{Query: "trace1"})
 		require.NoError(t, err)
@@ -625,31 +1171,50 @@ func TestDatasource(t *testing.T) {
 		response, err := queryDatasource(
 			ds,
 			datasource.QueryGetTimeSeriesServiceStatistics,
-			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: "traceID", Columns: []string{}},
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: `edge("a", id(name: "b", type: "AWS::Lambda"))`, Columns: []string{}},
 		)
 		require.NoError(t, err)
 		require.NoError(t, response.Responses["A"].Error)
 
-		require.Equal(t, 3, response.Responses["A"].Frames[0].Fields[0].Len())
-		require.Equal(t, 6, len(response.Responses["A"].Frames))
-		require.Equal(t, "Time", response.Responses["A"].Frames[0].Fields[0].Name)
-		require.Equal(t, "Throttle Count", response.Responses["A"].Frames[0].Fields[1].Name)
-		require.Equal(t, "Average Response Time", response.Responses["A"].Frames[5].Fields[1].Name)
+		// Single wide frame with only the edge statistics
+		require.Equal(t, 1, len(response.Responses["A"].Frames))
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 7, len(frame.Fields))
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "Time", frame.Fields[0].Name)
+		require.Equal(t, "Throttle Count", frame.Fields[1].Name)
+		require.Equal(t, "Average Response Time", frame.Fields[6].Name)
+		require.Equal(t, data.Labels{"source_service": "a", "target_service": "b", "target_type": "AWS::Lambda"}, frame.Fields[1].Labels)
 		require.Equal(
 			t,
 			time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC).String(),
-			response.Responses["A"].Frames[0].Fields[0].At(0).(*time.Time).String(),
+			frame.Fields[0].At(0).(*time.Time).String(),
+		)
+		require.Equal(t, int64(10), *frame.Fields[1].At(0).(*int64))
+		require.Equal(t, 3.14/80, *frame.Fields[6].At(0).(*float64))
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query for service", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: `service("mockServiceName") { fault }`, Columns: []string{}},
 		)
-		require.Equal(t, int64(10), *response.Responses["A"].Frames[0].Fields[1].At(0).(*int64))
-		require.Equal(t, int64(11), *response.Responses["A"].Frames[0].Fields[1].At(2).(*int64))
-		require.Equal(t, 3.14/80, *response.Responses["A"].Frames[5].Fields[1].At(0).(*float64))
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Only the service statistics are used
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, data.Labels{"service": "mockServiceName"}, frame.Fields[1].Labels)
+		require.Equal(t, int64(11), *frame.Fields[1].At(0).(*int64))
 	})
 
 	t.Run("getTimeSeriesServiceStatistics query with region", func(t *testing.T) {
 		response, err := queryDatasource(
 			ds,
 			datasource.QueryGetTimeSeriesServiceStatistics,
-			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: "traceID", Columns: []string{}, Region: "us-east-1"},
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: `edge("a", "b")`, Columns: []string{}, Region: "us-east-1"},
 		)
 		require.NoError(t, err)
 		require.NoError(t, response.Responses["A"].Error)
@@ -657,9 +1222,14 @@ func TestDatasource(t *testing.T) {
 		// expect different time as a stand-in for different results based on region, notice 13
 		require.Equal(
 			t,
-			time.Date(2020, 6, 20, 1, 13, 1, 0, time.UTC).String(),
+			time.Date(2020, 6, 20, 1, 1, 1, 0, time.UTC).String(),
 			response.Responses["A"].Frames[0].Fields[0].At(0).(*time.Time).String(),
 		)
+		require.Equal(
+			t,
+			time.Date(2020, 6, 20, 1, 13, 1, 0, time.UTC).String(),
+			response.Responses["A"].Frames[0].Fields[0].At(1).(*time.Time).String(),
+		)
 	})
 
 	t.Run("getTimeSeriesServiceStatistics query returns filtered columns", func(t *testing.T) {
@@ -671,9 +1241,65 @@ func TestDatasource(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, response.Responses["A"].Error)
 
-		require.Equal(t, 2, len(response.Responses["A"].Frames))
-		require.Equal(t, "Success Count", response.Responses["A"].Frames[0].Fields[1].Name)
-		require.Equal(t, "Fault Count", response.Responses["A"].Frames[1].Fields[1].Name)
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 3, len(frame.Fields))
+		require.Equal(t, "Success Count", frame.Fields[1].Name)
+		require.Equal(t, "Fault Count", frame.Fields[2].Name)
+		// No labels when the query is not a service or edge selector
+		require.Nil(t, frame.Fields[1].Labels)
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query returns computed columns", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{
+				Query:   `edge("a", "b")`,
+				Columns: []string{"Computed.P99ResponseTime", "Computed.ErrorRate", "Computed.FaultRate", "Computed.ThrottleRate", "Computed.RequestsPerSecond"},
+			},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 6, len(frame.Fields))
+		require.Equal(t, "p99 Response Time", frame.Fields[1].Name)
+		require.Equal(t, 42.42, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, float64(25), *frame.Fields[2].At(0).(*float64))
+		require.Equal(t, float64(25), *frame.Fields[3].At(0).(*float64))
+		require.Equal(t, 12.5, *frame.Fields[4].At(0).(*float64))
+		// 80 requests in 60s default resolution
+		require.Equal(t, 80.0/60, *frame.Fields[5].At(0).(*float64))
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query with unknown column", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: "traceID", Columns: []string{"Computed.Unknown"}},
+		)
+		require.NoError(t, err)
+		require.ErrorContains(t, response.Responses["A"].Error, "unknown column: Computed.Unknown")
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query with forecast", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: `service("mockServiceName")`, Columns: []string{"TotalCount"}, Forecast: true},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Fault count is added so the bounds can be compared with it
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 5, len(frame.Fields))
+		require.Equal(t, "Fault Count", frame.Fields[2].Name)
+		require.Equal(t, "Fault Count Upper Bound", frame.Fields[3].Name)
+		require.Equal(t, "Fault Count Lower Bound", frame.Fields[4].Name)
+		require.Equal(t, int64(20), *frame.Fields[2].At(0).(*int64))
+		require.Equal(t, int64(30), *frame.Fields[3].At(0).(*int64))
+		require.Equal(t, int64(5), *frame.Fields[4].At(0).(*int64))
 	})
 
 	t.Run("getTimeSeriesServiceStatistics query with all columns selected", func(t *testing.T) {
@@ -685,19 +1311,52 @@ func TestDatasource(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, response.Responses["A"].Error)
 
-		require.Equal(t, 3, response.Responses["A"].Frames[0].Fields[0].Len())
-		require.Equal(t, 6, len(response.Responses["A"].Frames))
-		require.Equal(t, "Time", response.Responses["A"].Frames[0].Fields[0].Name)
-		require.Equal(t, "Throttle Count", response.Responses["A"].Frames[0].Fields[1].Name)
-		require.Equal(t, "Average Response Time", response.Responses["A"].Frames[5].Fields[1].Name)
-		require.Equal(
-			t,
-			time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC).String(),
-			response.Responses["A"].Frames[0].Fields[0].At(0).(*time.Time).String(),
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 7, len(frame.Fields))
+		require.Equal(t, "Throttle Count", frame.Fields[1].Name)
+		require.Equal(t, "Average Response Time", frame.Fields[6].Name)
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query grouped by service", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Query: "fault = true", Columns: []string{"TotalCount"}, GroupBy: datasource.GroupByService},
 		)
-		require.Equal(t, int64(10), *response.Responses["A"].Frames[0].Fields[1].At(0).(*int64))
-		require.Equal(t, int64(11), *response.Responses["A"].Frames[0].Fields[1].At(2).(*int64))
-		require.Equal(t, 3.14/80, *response.Responses["A"].Frames[5].Fields[1].At(0).(*float64))
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frames := response.Responses["A"].Frames
+		require.Equal(t, 2, len(frames))
+		require.Equal(t, data.Labels{"service": "mockServiceName", "type": "AWS::EC2::Instance", "account": "testAccount1"}, frames[0].Fields[1].Labels)
+		require.Equal(t, data.Labels{"service": "mockServiceName2", "type": "AWS::DynamoDB::Table", "account": "testAccount2"}, frames[1].Fields[1].Labels)
+		require.Equal(t, int64(80), *frames[0].Fields[1].At(0).(*int64))
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query grouped by edge", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{Columns: []string{"TotalCount"}, GroupBy: datasource.GroupByEdge},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frames := response.Responses["A"].Frames
+		require.Equal(t, 1, len(frames))
+		require.Equal(t, "mockServiceName", frames[0].Fields[1].Labels["source_service"])
+		require.Equal(t, "mockServiceName2", frames[0].Fields[1].Labels["target_service"])
+		require.Equal(t, 2, frames[0].Rows())
+	})
+
+	t.Run("getTimeSeriesServiceStatistics query with unknown group by", func(t *testing.T) {
+		response, err := queryDatasource(
+			ds,
+			datasource.QueryGetTimeSeriesServiceStatistics,
+			datasource.GetTimeSeriesServiceStatisticsQueryData{GroupBy: "operation"},
+		)
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
 	})
 
 	t.Run("getTraceSummaries query", func(t *testing.T) {
@@ -727,17 +1386,284 @@ func TestDatasource(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, response.Responses["A"].Error)
 
-		// Bit simplistic test but right now we just send each service as a json to frontend and do transform there.
+		require.Equal(t, 2, len(response.Responses["A"].Frames))
+		nodes := response.Responses["A"].Frames[0]
+		require.Equal(t, "nodes", nodes.Name)
+		require.Equal(t, 2, nodes.Rows())
+		require.Equal(t, "0", nodes.Fields[0].At(0))
+		require.Equal(t, "mockServiceName", nodes.Fields[1].At(0))
+		require.Equal(t, "AWS::EC2::Instance", nodes.Fields[2].At(0))
+		// average response time in ms
+		require.Equal(t, float64(50), nodes.Fields[3].At(0))
+		// requests per minute
+		require.Equal(t, float64(10), *nodes.Fields[4].At(0).(*float64))
+		require.Nil(t, nodes.Fields[4].At(1))
+		// success, faults, errors without throttles, throttles
+		require.Equal(t, 0.6, nodes.Fields[5].At(0))
+		require.Equal(t, 0.2, nodes.Fields[6].At(0))
+		require.Equal(t, 0.1, nodes.Fields[7].At(0))
+		require.Equal(t, 0.1, nodes.Fields[8].At(0))
+
+		edges := response.Responses["A"].Frames[1]
+		require.Equal(t, "edges", edges.Name)
+		require.Equal(t, 1, edges.Rows())
+		require.Equal(t, "0__1", edges.Fields[0].At(0))
+		require.Equal(t, "0", edges.Fields[1].At(0))
+		require.Equal(t, "mockServiceName", edges.Fields[2].At(0))
+		require.Equal(t, "1", edges.Fields[3].At(0))
+		require.Equal(t, "mockServiceName2", edges.Fields[4].At(0))
+		require.Equal(t, float64(20), edges.Fields[5].At(0))
+		require.Equal(t, float64(5), *edges.Fields[6].At(0).(*float64))
+	})
+
+	t.Run("getServiceMap query with legacy format", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, LegacyFormat: true})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Legacy format sends each service as a json to frontend and does the transform there.
 		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, "ServiceMap", frame.Name)
 		require.Equal(t, 2, frame.Fields[0].Len()) // 2 because of the 2 services added to the mock
 	})
 
+	t.Run("getServiceMap query with compare time shift", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, CompareTimeShift: "1d"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Mock returns the same graph for both time ranges
+		nodes := response.Responses["A"].Frames[0]
+		require.Equal(t, 2, nodes.Rows())
+		require.Equal(t, "mockServiceName (AWS::EC2::Instance) testAccount1", nodes.Fields[0].At(0))
+		status, _ := nodes.FieldByName("detail__status")
+		require.Equal(t, datasource.ServiceMapStatusUnchanged, status.At(0))
+		responseTimeDelta, _ := nodes.FieldByName("detail__responseTimeDelta")
+		require.Equal(t, float64(0), *responseTimeDelta.At(0).(*float64))
+
+		edges := response.Responses["A"].Frames[1]
+		require.Equal(t, 1, edges.Rows())
+	})
+
+	t.Run("getServiceMap query with invalid compare time shift", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, CompareTimeShift: "yesterday"})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
+	t.Run("getServiceMap query with compare time shift and legacy format", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, CompareTimeShift: "1h", LegacyFormat: true})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+		require.Equal(t, backend.ErrorSourceDownstream, response.Responses["A"].ErrorSource)
+	})
+
+	t.Run("getServiceMap query with invalid focus direction", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, FocusService: "mockServiceName", FocusDirection: "sideways"})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+		require.Equal(t, backend.ErrorSourceDownstream, response.Responses["A"].ErrorSource)
+	})
+
+	t.Run("getHistogram query for service", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetHistogram, datasource.GetHistogramQueryData{ServiceName: "mockServiceName"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		// sorted by value
+		require.Equal(t, 0.01, frame.Fields[0].At(0))
+		require.Equal(t, int32(40), frame.Fields[1].At(0))
+		require.Equal(t, 0.05, frame.Fields[0].At(1))
+		require.Equal(t, int32(60), frame.Fields[1].At(1))
+	})
+
+	t.Run("getHistogram query for service duration", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetHistogram, datasource.GetHistogramQueryData{ServiceName: "mockServiceName", Histogram: datasource.HistogramDuration})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, 0.06, frame.Fields[0].At(0))
+		require.Equal(t, int32(100), frame.Fields[1].At(0))
+	})
+
+	t.Run("getHistogram query for edge", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetHistogram, datasource.GetHistogramQueryData{ServiceName: "mockServiceName", TargetServiceName: "mockServiceName2", TargetServiceType: "AWS::DynamoDB::Table"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, 0.02, frame.Fields[0].At(0))
+		require.Equal(t, int32(50), frame.Fields[1].At(0))
+	})
+
+	t.Run("getHistogram query over time", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetHistogram, datasource.GetHistogramQueryData{ServiceName: "mockServiceName", OverTime: true})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, data.FrameType("heatmap-cells"), frame.Meta.Type)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, time.Date(2020, 6, 20, 1, 0, 1, 0, time.UTC), frame.Fields[0].At(0))
+		require.Equal(t, 42.42, frame.Fields[1].At(0))
+		require.Equal(t, int32(5), frame.Fields[2].At(0))
+	})
+
+	t.Run("getHistogram query without service", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetHistogram, datasource.GetHistogramQueryData{})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
+	t.Run("getAlertingMetric query for active insights", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{Metric: datasource.AlertingMetricActiveInsights})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Count for each of the groups
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, float64(1), frame.Fields[1].At(0))
+		require.Equal(t, "group", frame.Fields[2].Name)
+		require.Equal(t, "Default", frame.Fields[2].At(0))
+		require.Equal(t, "GroupTest", frame.Fields[2].At(1))
+	})
+
+	t.Run("getAlertingMetric query for root cause fault percent", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{Metric: datasource.AlertingMetricRootCauseFaultPercent})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, float64(100), frame.Fields[1].At(0))
+		require.Equal(t, "faulty_service_name_1 (faulty_service_type_1)", frame.Fields[2].At(0))
+	})
+
+	t.Run("getAlertingMetric query for trace count", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{Metric: datasource.AlertingMetricTraceCount, Query: "fault = true"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// The mock returns the same traces for each of the four parallel requests
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, float64(8), frame.Fields[1].At(0))
+		require.Equal(t, "fault = true", frame.Fields[2].At(0))
+	})
+
+	t.Run("getAlertingMetric query for trace count in a group", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{
+			Metric: datasource.AlertingMetricTraceCount,
+			Query:  "fault = true",
+			Group:  &xraytypes.Group{GroupName: aws.String("mockGroup"), FilterExpression: aws.String(mockGroupFilterExpression)},
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, float64(4), frame.Fields[1].At(0))
+		require.Equal(t, "fault = true", frame.Fields[2].At(0))
+	})
+
+	t.Run("getAlertingMetric query for trace count with the group filter already applied", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{
+			Metric:             datasource.AlertingMetricTraceCount,
+			Query:              mockGroupFilterExpression + " AND fault = true",
+			Group:              &xraytypes.Group{GroupName: aws.String("mockGroup"), FilterExpression: aws.String(mockGroupFilterExpression)},
+			GroupFilterApplied: true,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, float64(8), frame.Fields[1].At(0))
+	})
+
+	t.Run("getAlertingMetric query for SLO attainment", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{Metric: datasource.AlertingMetricSLOAttainment})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// SLOs without attainment are skipped
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, 99.5, frame.Fields[1].At(0))
+		require.Equal(t, "testSLO", frame.Fields[2].At(0))
+	})
+
+	t.Run("getAlertingMetric query with unknown metric", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetAlertingMetric, datasource.GetAlertingMetricQueryData{Metric: "unknown"})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
+	t.Run("getTraceRate query", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetTraceRate, datasource.GetTraceRateQueryData{Query: "service(\"mockServiceName\")"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesWide, frame.Meta.Type)
+		require.Equal(t, 5, len(frame.Fields))
+		require.Equal(t, "Total Count", frame.Fields[1].Name)
+		require.Equal(t, "Throttle Count", frame.Fields[4].Name)
+		require.Empty(t, frame.Meta.Notices)
+	})
+
+	t.Run("getInsightDetails query", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetInsightDetails, datasource.GetInsightDetailsQueryData{InsightId: "id"})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+		require.Len(t, response.Responses["A"].Frames, 4)
+
+		// Events are sorted by time
+		events := response.Responses["A"].Frames[0]
+		require.Equal(t, 2, events.Rows())
+		require.Equal(t, "First event", events.Fields[1].At(0))
+		require.JSONEq(t, `["graf2 (AWS2)"]`, string(events.Fields[8].At(0).(json.RawMessage)))
+		require.Equal(t, "Second event", events.Fields[1].At(1))
+		require.Equal(t, int64(5), *events.Fields[2].At(1).(*int64))
+
+		impact := response.Responses["A"].Frames[1]
+		require.Equal(t, 2, impact.Rows())
+		require.Equal(t, "Client requests", impact.Fields[0].At(0))
+		require.Equal(t, int64(100), *impact.Fields[3].At(0).(*int64))
+		require.Equal(t, "Root cause service graf (AWS)", impact.Fields[0].At(1))
+		require.Equal(t, int64(20), *impact.Fields[1].At(1).(*int64))
+
+		nodes := response.Responses["A"].Frames[2]
+		require.Equal(t, 3, nodes.Rows())
+		require.Equal(t, float64(1), nodes.Fields[4].At(0))
+		require.Equal(t, float64(1), nodes.Fields[5].At(1))
+		require.Equal(t, float64(1), nodes.Fields[6].At(2))
+
+		// Edge to the missing service is skipped
+		edges := response.Responses["A"].Frames[3]
+		require.Equal(t, 2, edges.Rows())
+		require.Equal(t, "1__2", edges.Fields[0].At(1))
+	})
+
+	t.Run("getInsightDetails query for missing insight", func(t *testing.T) {
+		response, err := queryDatasource(ds, datasource.QueryGetInsightDetails, datasource.GetInsightDetailsQueryData{InsightId: "notFound"})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+
+		response, err = queryDatasource(ds, datasource.QueryGetInsightDetails, datasource.GetInsightDetailsQueryData{})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
 	t.Run("getServiceMap query with region", func(t *testing.T) {
-		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, Region: "us-east-1"})
+		response, err := queryDatasource(ds, datasource.QueryGetServiceMap, datasource.GetServiceMapQueryData{Group: &xraytypes.Group{}, Region: "us-east-1", LegacyFormat: true})
 		require.NoError(t, err)
 		require.NoError(t, response.Responses["A"].Error)
 
-		// Bit simplistic test but right now we just send each service as a json to frontend and do transform there.
 		frame := response.Responses["A"].Frames[0]
 		require.Equal(t, 2, frame.Fields[0].Len())
 		require.True(t, strings.Contains(frame.Fields[0].At(0).(string), "mockServiceName-us-east-1"))
@@ -847,6 +1773,110 @@ func TestDatasource(t *testing.T) {
 		require.Equal(t, expectedFrame, *frame)
 	})
 
+	t.Run("getServiceDetails query", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceDetails, "region": "us-east-1",
+			"serviceString": `{"Environment":"eks:app-signals-demo/default","Name":"billing-service-python","Type":"Service"}`,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+		require.Len(t, response.Responses["A"].Frames, 3)
+
+		expectedAttributesFrame := data.Frame{
+			Name: "ServiceAttributes",
+			Fields: []*data.Field{
+				data.NewField("Key", nil, []string{"EKS.Cluster", "K8s.Namespace", "PlatformType", "Telemetry.Agent", "Telemetry.SDK"}),
+				data.NewField("Value", nil, []string{"app-signals-demo", "default", "AWS::EKS", "agent", "sdk"}),
+			},
+		}
+		require.Equal(t, expectedAttributesFrame, *response.Responses["A"].Frames[0])
+
+		expectedMetricsFrame := data.Frame{
+			Name: "ServiceMetricReferences",
+			Fields: []*data.Field{
+				data.NewField("MetricName", nil, []*string{aws.String("Latency"), aws.String("Fault")}),
+				data.NewField("MetricType", nil, []*string{aws.String("LATENCY"), aws.String("FAULT")}),
+				data.NewField("Namespace", nil, []*string{aws.String("ApplicationSignals"), aws.String("ApplicationSignals")}),
+				data.NewField("AccountId", nil, []*string{nil, aws.String("569069006612")}),
+				data.NewField("Dimensions", nil, []*string{aws.String(`Service="billing-service-python"`), nil}),
+			},
+		}
+		require.Equal(t, expectedMetricsFrame, *response.Responses["A"].Frames[1])
+
+		expectedLogGroupsFrame := data.Frame{
+			Name: "ServiceLogGroups",
+			Fields: []*data.Field{
+				data.NewField("LogGroupName", nil, []string{"/aws/application-signals/data", "/aws/containerinsights/app-signals-demo/application"}),
+				data.NewField("AwsAccountId", nil, []string{"569069006612", ""}),
+			},
+		}
+		require.Equal(t, expectedLogGroupsFrame, *response.Responses["A"].Frames[2])
+	})
+
+	t.Run("getServiceDetails query without service", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceDetails, "region": "us-east-1",
+		})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
+	t.Run("getServiceDependencyGraph query", func(t *testing.T) {
+		response, err := ds.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{{
+			RefID: "A",
+			TimeRange: backend.TimeRange{
+				From: time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC),
+				To:   time.Date(2025, time.March, 1, 13, 0, 0, 0, time.UTC),
+			},
+			JSON: []byte(`{"queryMode":"Services","serviceQueryType":"getServiceDependencyGraph","region":"us-east-1","serviceString":"{\"Environment\":\"eks:app-signals-demo/default\",\"Name\":\"billing-service-python\",\"Type\":\"Service\"}"}`),
+		}}})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+		require.Len(t, response.Responses["A"].Frames, 2)
+
+		nodes := response.Responses["A"].Frames[0]
+		require.Equal(t, "nodes", nodes.Name)
+		require.Equal(t, 4, nodes.Rows())
+		require.Equal(t, "billing-service-python", nodes.Fields[1].At(0))
+		require.Equal(t, "discovery-server:8761", nodes.Fields[1].At(1))
+		require.Equal(t, "external-server:8761", nodes.Fields[1].At(2))
+		require.Equal(t, "pet-clinic-frontend-java", nodes.Fields[1].At(3))
+		require.Equal(t, "RemoteService", nodes.Fields[2].At(2))
+
+		edges := response.Responses["A"].Frames[1]
+		require.Equal(t, "edges", edges.Name)
+		require.Equal(t, 5, edges.Rows())
+		require.Equal(t, "pet-clinic-frontend-java", edges.Fields[2].At(2))
+		require.Equal(t, "billing-service-python", edges.Fields[4].At(2))
+
+		// The mock returns 3 points with the number of the query as value, the frontend calls billing with the fifth
+		// and sixth queries: the sum of the latency and the number of requests.
+		require.InDelta(t, 15.0/18, edges.Fields[5].At(2), 0.0001)
+		require.InDelta(t, 18.0/60, *edges.Fields[6].At(2).(*float64), 0.0001)
+		// Discovery server is not instrumented so it gets the statistics of the calls made to it, the same metric is
+		// counted once even if it is referenced by several edges.
+		require.Equal(t, edges.Fields[5].At(0), nodes.Fields[3].At(1))
+		require.Equal(t, edges.Fields[6].At(0), nodes.Fields[4].At(1))
+	})
+
+	t.Run("getServiceDependencyGraph query with too many hops", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceDependencyGraph, "region": "us-east-1",
+			"serviceString": `{"Name":"billing-service-python","Type":"Service"}`, "depth": 10,
+		})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
+	t.Run("getServiceDependencyGraph query with unknown direction", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceDependencyGraph, "region": "us-east-1",
+			"serviceString": `{"Name":"billing-service-python","Type":"Service"}`, "direction": "sideways",
+		})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
 	t.Run("listServiceOperations query", func(t *testing.T) {
 		service := map[string]string{
 			"AwsAccountId": "569069006612",
@@ -911,6 +1941,49 @@ func TestDatasource(t *testing.T) {
 		require.Equal(t, expectedFrame, *frame)
 	})
 
+	t.Run("listServiceDependents query", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryListServiceDependents, "region": "us-east-1",
+			"ServiceString": `{"Environment":"eks:app-signals-demo/default","Name":"billing-service-python","Type":"Service"}`,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		expectedFrame := data.Frame{
+			Name: "ListServiceDependents",
+			Fields: []*data.Field{
+				data.NewField("OperationName", nil, []*string{aws.String("InternalOperation")}),
+				data.NewField("DependentKeyAttributes", nil, []*string{aws.String("Environment:eks:app-signals-demo/default, Name:pet-clinic-frontend-java, Type:Service")}),
+				data.NewField("DependentOperationName", nil, []*string{aws.String("GET /api/billing")}),
+				data.NewField("MetricName", nil, []*string{aws.String("Latency")}),
+				data.NewField("MetricType", nil, []*string{aws.String("LATENCY")}),
+				data.NewField("Namespace", nil, []*string{aws.String("AppSignals")}),
+				data.NewField("AccountId", nil, []*string{nil}),
+				data.NewField("Dimensions", nil, []*string{aws.String(`Service="pet-clinic-frontend-java"`)}),
+			},
+		}
+		require.Equal(t, expectedFrame, *frame)
+	})
+
+	t.Run("listServiceDependencies query with JSON attributes format", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryListServiceDependencies, "region": "us-east-1",
+			"ServiceString": `{"Name":"datasources-graphite-latest-07978676","Type":"Service"}`, "attributesFormat": datasource.AttributesFormatJSON,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		dependencyKeyAttributes, _ := frame.FieldByName("DependencyKeyAttributes")
+		require.Equal(t, data.FieldTypeNullableJSON, dependencyKeyAttributes.Type())
+		require.JSONEq(t, `{"Name":"discovery-server:8761","Type":"InternalService"}`, string(*dependencyKeyAttributes.At(0).(*json.RawMessage)))
+		dimensions, _ := frame.FieldByName("Dimensions")
+		require.Equal(t, data.FieldTypeNullableJSON, dimensions.Type())
+		require.JSONEq(t, `{"foo":"bar","baz":"tab"}`, string(*dimensions.At(0).(*json.RawMessage)))
+		require.Nil(t, dimensions.At(1))
+	})
+
 	t.Run("listServiceLevelObjectives query", func(t *testing.T) {
 		service := map[string]string{
 			"AwsAccountId": "569069006612",
@@ -940,11 +2013,228 @@ func TestDatasource(t *testing.T) {
 				data.NewField("KeyAttributes", nil, []*string{
 					aws.String("AwsAccountId:000000000000, Environment:eks:app-signals-demo/default, Name:vets-service-java"),
 					aws.String("AwsAccountId:999999999999, Name:pet-clinic-frontend-java, Type:Service")}),
+				data.NewField("ServiceType", nil, []string{"", "Service"}),
+				data.NewField("ServiceResourceType", nil, []string{"", ""}),
+				data.NewField("ServiceName", nil, []string{"vets-service-java", "pet-clinic-frontend-java"}),
+				data.NewField("ServiceIdentifier", nil, []string{"", ""}),
+				data.NewField("ServiceEnvironment", nil, []string{"eks:app-signals-demo/default", ""}),
+				data.NewField("AwsAccountId", nil, []string{"000000000000", "999999999999"}),
+				data.NewField("MetricSourceType", nil, []string{"ServiceOperation", "ServiceDependency"}),
+				data.NewField("EvaluationType", nil, []string{"PeriodBased", "RequestBased"}),
+				data.NewField("DependencyKeyAttributes", nil, []*string{nil, aws.String("ResourceType:AWS::DynamoDB::Table, Type:AWS::Resource")}),
+				data.NewField("DependencyOperationName", nil, []*string{nil, aws.String("PutItem")}),
+				data.NewField("Arn", nil, []*string{nil, nil}),
 			},
 		}
 		require.Equal(t, expectedFrame, *frame)
 	})
 
+	t.Run("listServiceLevelObjectives query without service filtered by metric source type", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryListServiceLevelObjectives, "region": "us-east-1",
+			"metricSourceTypes": []string{"ServiceDependency"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "Latency for Frontend InternalOperationInternalOperation", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, "pet-clinic-frontend-java", frame.Fields[6].At(0))
+		require.Equal(t, "ServiceDependency", frame.Fields[10].At(0))
+		require.Equal(t, "PutItem", *frame.Fields[13].At(0).(*string))
+	})
+
+	t.Run("listServiceLevelObjectives query with JSON attributes format", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryListServiceLevelObjectives, "region": "us-east-1",
+			"attributesFormat": datasource.AttributesFormatJSON,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		keyAttributes, _ := frame.FieldByName("KeyAttributes")
+		require.JSONEq(t, `{"AwsAccountId":"999999999999","Name":"pet-clinic-frontend-java","Type":"Service"}`, string(*keyAttributes.At(1).(*json.RawMessage)))
+		dependencyKeyAttributes, _ := frame.FieldByName("DependencyKeyAttributes")
+		require.Nil(t, dependencyKeyAttributes.At(0))
+		require.JSONEq(t, `{"ResourceType":"AWS::DynamoDB::Table","Type":"AWS::Resource"}`, string(*dependencyKeyAttributes.At(1).(*json.RawMessage)))
+	})
+
+	t.Run("listServices query with JSON attributes format", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]string{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryListServices, "region": "us-east-1",
+			"attributesFormat": datasource.AttributesFormatJSON,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		keyAttributes, _ := response.Responses["A"].Frames[0].FieldByName("KeyAttributes")
+		require.Equal(t, data.FieldTypeNullableJSON, keyAttributes.Type())
+		require.JSONEq(t, `{"Environment":"eks:app-signals-demo/default","Name":"billing-service-python","Type":"Service"}`, string(*keyAttributes.At(0).(*json.RawMessage)))
+	})
+
+	t.Run("getServiceLevelObjectiveDetails query", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceLevelObjectiveDetails, "region": "us-east-1",
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+		require.Len(t, response.Responses["A"].Frames, 1)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		row := frame.RowCopy(0)
+		require.Equal(t, "testSLO", row[0])
+		require.Equal(t, "Latency of vets service", row[1])
+		require.Equal(t, "PeriodBased", row[2])
+		require.Equal(t, "LATENCY", row[3])
+		require.Equal(t, "GET /**", row[4])
+		require.Equal(t, 200.0, *row[5].(*float64))
+		require.Equal(t, "LessThan", row[6])
+		require.Equal(t, "7 day rolling", row[7])
+		require.Equal(t, 99.9, *row[8].(*float64))
+		require.Equal(t, 99.5, *row[10].(*float64))
+		require.Equal(t, 25.0, *row[11].(*float64))
+		require.Equal(t, 75.0, *row[12].(*float64))
+		require.Equal(t, "WARNING", row[13])
+
+		// Request based SLO without budget data
+		row = frame.RowCopy(1)
+		require.Equal(t, "AVAILABILITY", row[3])
+		require.Equal(t, "1 month calendar", row[7])
+		require.Nil(t, row[10])
+		require.Nil(t, row[11])
+	})
+
+	t.Run("getServiceLevelObjectiveHistory query", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceLevelObjectiveHistory,
+		})
+		from := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+		response, err := ds.QueryData(
+			context.Background(),
+			&backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A", JSON: jsonData, TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)}}}},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+		require.Equal(t, 62, frame.Rows())
+
+		// The burn rate of the first point is computed from the report a step before the time range
+		row := frame.RowCopy(0)
+		require.Equal(t, from, row[0])
+		require.Equal(t, 0.0, *row[3].(*float64))
+		require.Equal(t, 0.0, row[4])
+		require.Equal(t, "testSLO", row[5])
+
+		// At 12:30 the remaining 25% of a 7 day budget is used in two minutes
+		row = frame.RowCopy(30)
+		require.Equal(t, sloBreachStart, row[0])
+		require.Equal(t, 0.0, *row[2].(*float64))
+		require.InDelta(t, 1260.0, *row[3].(*float64), 0.001)
+		require.Equal(t, 1.0, row[4])
+
+		// The budget growing back is not a negative burn rate
+		row = frame.RowCopy(42)
+		require.Equal(t, sloBreachStart.Add(12*time.Minute), row[0])
+		require.Equal(t, 0.0, *row[3].(*float64))
+		require.Equal(t, 0.0, row[4])
+	})
+
+	t.Run("getServiceLevelObjectiveBreaches query", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceLevelObjectiveBreaches,
+		})
+		from := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+		response, err := ds.QueryData(
+			context.Background(),
+			&backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A", JSON: jsonData, TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)}}}},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, "ServiceLevelObjectiveBreaches", frame.Name)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, sloBreachStart, frame.Fields[0].At(0))
+		// The last breached point is at 12:40, the breach ends with the next point
+		require.Equal(t, sloBreachStart.Add(12*time.Minute), frame.Fields[1].At(0))
+		require.Equal(t, "SLO testSLO breached", frame.Fields[2].At(0))
+		require.Equal(t, "Attainment: 99.00%\nBudget remaining: 0.00%\nBurn rate: 1260.00", frame.Fields[3].At(0))
+		require.Equal(t, "SLO,Breached,testSLO", frame.Fields[4].At(0))
+	})
+
+	t.Run("getServiceLevelObjectiveBreaches query with burn rate threshold", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceLevelObjectiveBreaches, "burnRateThreshold": 0.5,
+		})
+		// Only the first minutes of the breach are in the time range
+		from := sloBreachStart.Add(-20 * time.Minute)
+		response, err := ds.QueryData(
+			context.Background(),
+			&backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A", JSON: jsonData, TimeRange: backend.TimeRange{From: from, To: sloBreachStart.Add(4 * time.Minute)}}}},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, sloBreachStart, frame.Fields[0].At(0))
+		// The breach continues after the time range so it ends with it
+		require.Equal(t, sloBreachStart.Add(4*time.Minute), frame.Fields[1].At(0))
+	})
+
+	t.Run("getServiceMetrics query", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceMetrics,
+			"serviceString": `{"Name":"vets-service-java","Type":"Service"}`, "metricType": "Latency",
+		})
+		from := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+		response, err := ds.QueryData(
+			context.Background(),
+			&backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A", JSON: jsonData, Interval: 5 * time.Minute, TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)}}}},
+		)
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		// Only InternalOperation has a latency metric
+		require.Len(t, response.Responses["A"].Frames, 1)
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, "Latency", frame.Name)
+		require.Equal(t, 3, frame.Rows())
+		// CloudWatch keeps data this old only with an hour resolution so the 5 minute interval is rounded up
+		require.Equal(t, from.Add(2*time.Hour), frame.Fields[0].At(2))
+		require.Equal(t, 1.0, frame.Fields[1].At(2))
+		require.Equal(t, data.Labels{"operation": "InternalOperation"}, frame.Fields[1].Labels)
+		require.Equal(t, "ms", frame.Fields[1].Config.Unit)
+	})
+
+	t.Run("getServiceMetrics query for dependencies", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceMetrics,
+			"serviceString": `{"Name":"vets-service-java","Type":"Service"}`, "metricType": "error", "dependencies": true,
+		})
+		require.NoError(t, err)
+		require.NoError(t, response.Responses["A"].Error)
+
+		require.Len(t, response.Responses["A"].Frames, 1)
+		frame := response.Responses["A"].Frames[0]
+		require.Equal(t, "Error", frame.Name)
+		require.Equal(t, data.Labels{"operation": "ExternalOperation", "dependency": "external-server:8761", "dependencyOperation": "GET /eureka"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("getServiceMetrics query without metric type", func(t *testing.T) {
+		response, err := queryDatasource(ds, "", map[string]interface{}{
+			"queryMode": datasource.ModeServices, "serviceQueryType": datasource.QueryGetServiceMetrics,
+			"serviceString": `{"Name":"vets-service-java","Type":"Service"}`,
+		})
+		require.NoError(t, err)
+		require.Error(t, response.Responses["A"].Error)
+	})
+
 	t.Run("getGroups query", func(t *testing.T) {
 		resp, err := queryDatasourceResource(ds, &backend.CallResourceRequest{
 			Path:   "/groups",
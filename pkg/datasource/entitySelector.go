@@ -0,0 +1,112 @@
+package datasource
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+var (
+	entitySelectorRegex = regexp.MustCompile(`^\s*(service|edge|id)\s*\(`)
+	quotedStringRegex   = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"`)
+	idFunctionRegex     = regexp.MustCompile(`^\s*id\s*\(([^)]*)\)`)
+	idArgumentRegex     = regexp.MustCompile(`(name|type|account\.id)\s*:\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// serviceSelector returns the X-Ray id function selecting the service by name and, if not empty, type and account.
+func serviceSelector(name string, serviceType string, accountId string) string {
+	arguments := []string{fmt.Sprintf("name: %s", strconv.Quote(name))}
+	if serviceType != "" {
+		arguments = append(arguments, fmt.Sprintf("type: %s", strconv.Quote(serviceType)))
+	}
+	if accountId != "" {
+		arguments = append(arguments, fmt.Sprintf("account.id: %s", strconv.Quote(accountId)))
+	}
+	return fmt.Sprintf("id(%s)", strings.Join(arguments, ", "))
+}
+
+// parseEntitySelector returns whether the expression starts with a service or an edge selector and labels with the
+// name, type and account of the selected services. Anything after the first selector is ignored. Kind is empty and
+// labels are nil if the expression does not start with a selector.
+func parseEntitySelector(expression string) (string, data.Labels) {
+	match := entitySelectorRegex.FindStringSubmatch(expression)
+	if match == nil {
+		return "", nil
+	}
+	rest := expression[len(match[0]):]
+
+	if match[1] == "id" {
+		end := strings.Index(rest, ")")
+		if end == -1 {
+			return "", nil
+		}
+		return GroupByService, idLabels("", rest[:end])
+	}
+
+	var entities []data.Labels
+	prefixes := []string{"", ""}
+	if match[1] == "edge" {
+		prefixes = []string{"source", "target"}
+	}
+	for len(entities) < 2 {
+		prefix := prefixes[len(entities)]
+		if quoted := quotedStringRegex.FindStringSubmatch(rest); quoted != nil {
+			entities = append(entities, data.Labels{labelKey(prefix, "service"): unquote(quoted[1])})
+			rest = rest[len(quoted[0]):]
+		} else if id := idFunctionRegex.FindStringSubmatch(rest); id != nil {
+			entities = append(entities, idLabels(prefix, id[1]))
+			rest = rest[len(id[0]):]
+		} else {
+			break
+		}
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+
+	if match[1] == "service" {
+		if len(entities) == 0 {
+			return GroupByService, nil
+		}
+		return GroupByService, entities[0]
+	}
+	labels := data.Labels{}
+	for _, entity := range entities {
+		for key, value := range entity {
+			labels[key] = value
+		}
+	}
+	return GroupByEdge, labels
+}
+
+// idLabels returns labels from the arguments of the id function, for example name: "A", type: "AWS::Lambda".
+func idLabels(prefix string, arguments string) data.Labels {
+	labels := data.Labels{}
+	for _, argument := range idArgumentRegex.FindAllStringSubmatch(arguments, -1) {
+		name := argument[1]
+		switch name {
+		case "name":
+			name = "service"
+		case "account.id":
+			name = "account"
+		}
+		labels[labelKey(prefix, name)] = unquote(argument[2])
+	}
+	return labels
+}
+
+func labelKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+func unquote(value string) string {
+	unquoted, err := strconv.Unquote(`"` + value + `"`)
+	if err != nil {
+		return value
+	}
+	return unquoted
+}
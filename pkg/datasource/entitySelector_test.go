@@ -0,0 +1,53 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntitySelector(t *testing.T) {
+	tests := []struct {
+		expression string
+		kind       string
+		labels     data.Labels
+	}{
+		{`service("A")`, GroupByService, data.Labels{"service": "A"}},
+		{`service("A") { fault = true }`, GroupByService, data.Labels{"service": "A"}},
+		{`service(id(name: "A", type: "AWS::Lambda", account.id: "123"))`, GroupByService, data.Labels{"service": "A", "type": "AWS::Lambda", "account": "123"}},
+		{`id(name: "say \"hi\"")`, GroupByService, data.Labels{"service": `say "hi"`}},
+		{`edge("A", "B")`, GroupByEdge, data.Labels{"source_service": "A", "target_service": "B"}},
+		{`edge(id(name: "A"), id(name: "B", type: "AWS::DynamoDB::Table"))`, GroupByEdge, data.Labels{"source_service": "A", "target_service": "B", "target_type": "AWS::DynamoDB::Table"}},
+		{`http.status = 500`, "", nil},
+		{``, "", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			kind, labels := parseEntitySelector(test.expression)
+			require.Equal(t, test.kind, kind)
+			require.Equal(t, test.labels, labels)
+		})
+	}
+}
+
+func TestGroupByEntitiesAccounts(t *testing.T) {
+	service := func(id int32, account string, edges ...xraytypes.Edge) xraytypes.Service {
+		return xraytypes.Service{ReferenceId: aws.Int32(id), Name: aws.String("A"), Type: aws.String("AWS::Lambda"), AccountId: aws.String(account), Edges: edges}
+	}
+	services := []xraytypes.Service{
+		service(0, "111", xraytypes.Edge{ReferenceId: aws.Int32(1)}),
+		service(1, "222"),
+	}
+
+	entities := groupByEntities(services, GroupByService, "fault = true")
+	require.Equal(t, 2, len(entities))
+	require.Equal(t, `service(id(name: "A", type: "AWS::Lambda", account.id: "111")) AND (fault = true)`, entities[0].selector)
+	require.Equal(t, `service(id(name: "A", type: "AWS::Lambda", account.id: "222")) AND (fault = true)`, entities[1].selector)
+
+	entities = groupByEntities(services, GroupByEdge, "")
+	require.Equal(t, 1, len(entities))
+	require.Equal(t, `edge(id(name: "A", type: "AWS::Lambda", account.id: "111"), id(name: "A", type: "AWS::Lambda", account.id: "222"))`, entities[0].selector)
+}
@@ -0,0 +1,242 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Needs to match AlertingMetric in frontend code
+const (
+	AlertingMetricActiveInsights        = "activeInsights"
+	AlertingMetricRootCauseFaultPercent = "rootCauseFaultPercent"
+	AlertingMetricTraceCount            = "traceCount"
+	AlertingMetricSLOAttainment         = "sloAttainment"
+)
+
+type GetAlertingMetricQueryData struct {
+	Metric string           `json:"metric"`
+	Region string           `json:"region"`
+	Query  string           `json:"query"`
+	Group  *xraytypes.Group `json:"group"`
+
+	// Set by the frontend when it already added the group filter expression to the query. Alert rules are evaluated
+	// without the frontend so the backend adds it to traceCount if this is not set.
+	GroupFilterApplied bool `json:"groupFilterApplied,omitempty"`
+
+	// Used to select the SLOs for sloAttainment, all the SLOs are used if not set.
+	ServiceString string `json:"serviceString,omitempty"`
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// getSingleAlertingMetric returns a single number for each group, service or SLO at the end of the time range. The
+// frame is in the long format with the labels as string fields, which is what alert rules expect.
+func (ds *Datasource) getSingleAlertingMetric(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetAlertingMetricQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleAlertingMetric", "RefID", query.RefID, "metric", queryData.Metric)
+
+	var frame *data.Frame
+	switch queryData.Metric {
+	case AlertingMetricActiveInsights:
+		frame, err = ds.getActiveInsightsMetric(ctx, query, pluginContext, queryData)
+	case AlertingMetricRootCauseFaultPercent:
+		frame, err = ds.getRootCauseFaultPercentMetric(ctx, query, pluginContext)
+	case AlertingMetricTraceCount:
+		frame, err = ds.getTraceCountMetric(ctx, query, pluginContext, queryData)
+	case AlertingMetricSLOAttainment:
+		frame, err = ds.getSLOAttainmentMetric(ctx, query, pluginContext, queryData)
+	default:
+		err = backend.DownstreamError(fmt.Errorf("unknown alerting metric: %s", queryData.Metric))
+	}
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// newAlertingMetricFrame returns a long format time series frame with a time, a value and a string field for each
+// of the labels.
+func newAlertingMetricFrame(name string, unit string, labels ...string) *data.Frame {
+	frame := data.NewFrame(
+		name,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField(name, nil, []float64{}).SetConfig(&data.FieldConfig{Unit: unit}),
+	)
+	for _, label := range labels {
+		frame.Fields = append(frame.Fields, data.NewField(label, nil, []string{}))
+	}
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesLong}
+	return frame
+}
+
+// getActiveInsightsMetric returns the count of active insights in each group, or in all the groups if the query does
+// not have one.
+func (ds *Datasource) getActiveInsightsMetric(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext, queryData *GetAlertingMetricQueryData) (*data.Frame, error) {
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return nil, backend.PluginError(err)
+	}
+
+	var groupNames []*string
+	if queryData.Group == nil || Dereference(queryData.Group.GroupName) == "All" {
+		groups, err := getGroupsFromXray(ctx, xrayClient)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			groupNames = append(groupNames, group.GroupName)
+		}
+	} else {
+		groupNames = []*string{queryData.Group.GroupName}
+	}
+
+	frame := newAlertingMetricFrame("Active Insights", "", "group")
+	for _, groupName := range groupNames {
+		pager := xray.NewGetInsightSummariesPaginator(xrayClient, &xray.GetInsightSummariesInput{
+			StartTime: &query.TimeRange.From,
+			EndTime:   &query.TimeRange.To,
+			States:    []xraytypes.InsightState{xraytypes.InsightStateActive},
+			GroupName: groupName,
+		})
+		count := 0
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, backend.DownstreamError(err)
+			}
+			for _, insight := range page.InsightSummaries {
+				if insight.State == xraytypes.InsightStateActive {
+					count++
+				}
+			}
+		}
+		frame.AppendRow(query.TimeRange.To, float64(count), Dereference(groupName))
+	}
+	return frame, nil
+}
+
+// getRootCauseFaultPercentMetric returns the percent of traces whose fault root cause is the service, for each of the
+// root cause services. It uses the same traces as the Fault Root Cause analytics query.
+func (ds *Datasource) getRootCauseFaultPercentMetric(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) (*data.Frame, error) {
+	const maxTraces = 10000
+	traces, _, err := ds.getTraceSummariesData(ctx, query, maxTraces, pluginContext)
+	if err != nil {
+		return nil, backend.DownstreamError(err)
+	}
+
+	processor := NewDataProcessor(QueryGetAnalyticsRootCauseFaultService)
+	processor.processTraces(traces)
+
+	var services []string
+	for service := range processor.counts {
+		// Traces without a fault
+		if service == "-" {
+			continue
+		}
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	frame := newAlertingMetricFrame("Fault Root Cause Percent", "percent", "service")
+	for _, service := range services {
+		frame.AppendRow(query.TimeRange.To, float64(processor.counts[service])/float64(processor.total)*100, service)
+	}
+	return frame, nil
+}
+
+// getTraceCountMetric returns the number of traces matching the filter expression in the time range.
+func (ds *Datasource) getTraceCountMetric(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext, queryData *GetAlertingMetricQueryData) (*data.Frame, error) {
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return nil, backend.PluginError(err)
+	}
+
+	var count int64
+	var notices []data.Notice
+	if queryData.Query == "" {
+		var groupName *string
+		if queryData.Group != nil {
+			groupName = queryData.Group.GroupName
+		}
+		count, err = getTracesCount(ctx, xrayClient, query.TimeRange.From, query.TimeRange.To, groupName)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// There is no API to count the traces matching a filter expression so they are fetched, sampled if there are
+		// too many of them.
+		const maxTraces = 10000
+		filterExpression := groupFilterExpression(queryData.Group, queryData.Query, queryData.GroupFilterApplied)
+		traces, sampling, err := getSampledTraceSummaries(ctx, xrayClient, query.TimeRange, filterExpression, nil, maxTraces)
+		if err != nil {
+			return nil, err
+		}
+		count = int64(math.Round(float64(len(traces)) / sampling))
+		if sampling < 1 {
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityInfo,
+				Text:     fmt.Sprintf("The count is estimated from a sample of %.2f%% of the traces.", sampling*100),
+			})
+		}
+	}
+
+	frame := newAlertingMetricFrame("Trace Count", "", "query")
+	frame.Meta.Notices = notices
+	frame.AppendRow(query.TimeRange.To, float64(count), queryData.Query)
+	return frame, nil
+}
+
+// groupFilterExpression returns the query limited to the traces of the group. GetTraceSummaries does not accept a group
+// so its filter expression is added to the query, unless the frontend already did that.
+func groupFilterExpression(group *xraytypes.Group, query string, groupFilterApplied bool) string {
+	if group == nil || Dereference(group.FilterExpression) == "" || groupFilterApplied {
+		return query
+	}
+	return fmt.Sprintf("(%s) AND (%s)", *group.FilterExpression, query)
+}
+
+// getSLOAttainmentMetric returns the attainment of each SLO at the end of the time range.
+func (ds *Datasource) getSLOAttainmentMetric(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext, queryData *GetAlertingMetricQueryData) (*data.Frame, error) {
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return nil, backend.PluginError(err)
+	}
+
+	sloIds, err := listSLOIds(ctx, appSignalsClient, queryData.ServiceString, queryData.OperationName)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := getSLOBudgetReports(ctx, appSignalsClient, sloIds, query.TimeRange.To)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := newAlertingMetricFrame("SLO Attainment", "percent", "slo")
+	for _, sloId := range sloIds {
+		report, ok := reports[sloId]
+		// Attainment is missing when the SLO does not have data yet
+		if !ok || report.Attainment == nil {
+			continue
+		}
+		frame.AppendRow(query.TimeRange.To, *report.Attainment, Dereference(report.Name))
+	}
+	return frame, nil
+}
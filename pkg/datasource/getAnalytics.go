@@ -29,7 +29,7 @@ func (ds *Datasource) getSingleAnalyticsQueryResult(ctx context.Context, query b
 	log.DefaultLogger.Debug("getSingleAnalyticsResult", "type", query.QueryType, "RefID", query.RefID)
 
 	const maxTraces = 10000
-	traces, err := ds.getTraceSummariesData(ctx, query, maxTraces, pluginContext)
+	traces, _, err := ds.getTraceSummariesData(ctx, query, maxTraces, pluginContext)
 
 	if err != nil {
 		log.DefaultLogger.Debug("getSingleAnalyticsResult", "error", err)
@@ -45,21 +45,33 @@ func (ds *Datasource) getSingleAnalyticsQueryResult(ctx context.Context, query b
 	}
 }
 
-func (ds *Datasource) getTraceSummariesData(ctx context.Context, query backend.DataQuery, maxTraces int, pluginContext backend.PluginContext) ([]xraytypes.TraceSummary, error) {
+// getTraceSummariesData returns up to about maxTraces trace summaries from the time range. If there are more traces
+// they are sampled and the returned sampling is the fraction of the traces that were kept.
+func (ds *Datasource) getTraceSummariesData(ctx context.Context, query backend.DataQuery, maxTraces int, pluginContext backend.PluginContext) ([]xraytypes.TraceSummary, float64, error) {
 	queryData := &GetAnalyticsQueryData{}
 	err := json.Unmarshal(query.JSON, queryData)
 	if err != nil {
-		return nil, backend.PluginError(err)
+		return nil, 0, backend.PluginError(err)
 	}
 
 	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
 	if err != nil {
-		return nil, backend.PluginError(err)
+		return nil, 0, backend.PluginError(err)
 	}
 
-	log.DefaultLogger.Debug("getTraceSummariesData", "query", queryData.Query)
+	var groupName *string
+	if queryData.Group != nil {
+		groupName = queryData.Group.GroupName
+	}
+	return getSampledTraceSummaries(ctx, xrayClient, query.TimeRange, queryData.Query, groupName, maxTraces)
+}
 
-	diff := query.TimeRange.To.Sub(query.TimeRange.From)
+// getSampledTraceSummaries returns up to about maxTraces trace summaries matching the filter expression, see
+// getTraceSummariesData. The group is only used to count the traces when there is no filter expression.
+func getSampledTraceSummaries(ctx context.Context, xrayClient XrayClient, timeRange backend.TimeRange, filterExpression string, groupName *string, maxTraces int) ([]xraytypes.TraceSummary, float64, error) {
+	log.DefaultLogger.Debug("getSampledTraceSummaries", "filterExpression", filterExpression)
+
+	diff := timeRange.To.Sub(timeRange.From)
 	diffQuarter := diff.Nanoseconds() / 4
 
 	var traces []xraytypes.TraceSummary
@@ -67,28 +79,24 @@ func (ds *Datasource) getTraceSummariesData(ctx context.Context, query backend.D
 	sampling := float64(1)
 	adaptiveSampling := true
 
-	if queryData.Query == "" {
-		var groupName *string
-		if queryData.Group != nil {
-			groupName = queryData.Group.GroupName
-		}
+	if filterExpression == "" {
 		// Get count of all the traces so we can compute sampling. The API used does not allow for filter expression so
 		// we can do this only if we don't have one.
-		count, err := getTracesCount(ctx, xrayClient, query.TimeRange.From, query.TimeRange.To, groupName)
+		count, err := getTracesCount(ctx, xrayClient, timeRange.From, timeRange.To, groupName)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		sampling = math.Min(float64(maxTraces)/float64(count), 1)
-		log.DefaultLogger.Debug("getTraceSummariesData static sampling", "sampling", sampling, "maxTraces", maxTraces, "count", count)
+		log.DefaultLogger.Debug("getSampledTraceSummaries static sampling", "sampling", sampling, "maxTraces", maxTraces, "count", count)
 		adaptiveSampling = false
 	}
 
 	for i := 0; i < 4; i++ {
 		requests = append(requests, makeRequest(
-			query.TimeRange.From.Add(time.Duration(diffQuarter*int64(i))),
-			query.TimeRange.From.Add(time.Duration(diffQuarter*int64(i+1))),
+			timeRange.From.Add(time.Duration(diffQuarter*int64(i))),
+			timeRange.From.Add(time.Duration(diffQuarter*int64(i+1))),
 			sampling,
-			queryData.Query,
+			filterExpression,
 		))
 	}
 
@@ -100,7 +108,7 @@ func (ds *Datasource) getTraceSummariesData(ctx context.Context, query backend.D
 		// Run the four parallel requests, returns when all are done
 		responses, err := runRequests(ctx, xrayClient, requests, tokens)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		// Append traces and get tokens for next page for each request
@@ -134,11 +142,11 @@ func (ds *Datasource) getTraceSummariesData(ctx context.Context, query backend.D
 				req.SamplingStrategy.Value = aws.Float64(sampling)
 			}
 
-			log.DefaultLogger.Debug("getTraceSummariesData", "len(traces)", originalLen, "maxTraces", maxTraces, "len(sampled)", len(traces), "newSampling", sampling)
+			log.DefaultLogger.Debug("getSampledTraceSummaries", "len(traces)", originalLen, "maxTraces", maxTraces, "len(sampled)", len(traces), "newSampling", sampling)
 		}
 	}
 
-	return traces, nil
+	return traces, sampling, nil
 }
 
 func makeRequest(from time.Time, to time.Time, sampling float64, filterExpression string) *xray.GetTraceSummariesInput {
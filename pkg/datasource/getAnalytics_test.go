@@ -121,6 +121,50 @@ func (client *XrayClientMock) GetGroups(_ context.Context, _ *xray.GetGroupsInpu
 	return nil, nil
 }
 
+func (client *XrayClientMock) GetInsight(_ context.Context, _ *xray.GetInsightInput, _ ...func(*xray.Options)) (*xray.GetInsightOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetInsightEvents(_ context.Context, _ *xray.GetInsightEventsInput, _ ...func(*xray.Options)) (*xray.GetInsightEventsOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetInsightImpactGraph(_ context.Context, _ *xray.GetInsightImpactGraphInput, _ ...func(*xray.Options)) (*xray.GetInsightImpactGraphOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetSamplingRules(_ context.Context, _ *xray.GetSamplingRulesInput, _ ...func(*xray.Options)) (*xray.GetSamplingRulesOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetSamplingStatisticSummaries(_ context.Context, _ *xray.GetSamplingStatisticSummariesInput, _ ...func(*xray.Options)) (*xray.GetSamplingStatisticSummariesOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetEncryptionConfig(_ context.Context, _ *xray.GetEncryptionConfigInput, _ ...func(*xray.Options)) (*xray.GetEncryptionConfigOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetIndexingRules(_ context.Context, _ *xray.GetIndexingRulesInput, _ ...func(*xray.Options)) (*xray.GetIndexingRulesOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetTraceSegmentDestination(_ context.Context, _ *xray.GetTraceSegmentDestinationInput, _ ...func(*xray.Options)) (*xray.GetTraceSegmentDestinationOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) StartTraceRetrieval(_ context.Context, _ *xray.StartTraceRetrievalInput, _ ...func(*xray.Options)) (*xray.StartTraceRetrievalOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) ListRetrievedTraces(_ context.Context, _ *xray.ListRetrievedTracesInput, _ ...func(*xray.Options)) (*xray.ListRetrievedTracesOutput, error) {
+	return nil, nil
+}
+
+func (client *XrayClientMock) GetRetrievedTracesGraph(_ context.Context, _ *xray.GetRetrievedTracesGraphInput, _ ...func(*xray.Options)) (*xray.GetRetrievedTracesGraphOutput, error) {
+	return nil, nil
+}
+
 func getXrayClientFactory(client XrayClient) XrayClientFactory {
 	return func(context.Context, backend.PluginContext, RequestSettings) (XrayClient, error) {
 		return client, nil
@@ -137,9 +181,9 @@ func TestGetAnalytics(t *testing.T) {
 			makeTrace("2020-09-16T00:00:04Z", "0", 100),
 		)
 		settings := awsds.AWSDatasourceSettings{}
-		ds := NewDatasource(context.Background(), getXrayClientFactory(xrayMock), getAppSignalsClient, settings)
+		ds := NewDatasource(context.Background(), getXrayClientFactory(xrayMock), getAppSignalsClient, getCloudWatchClient, settings)
 		// This should go happy path use 0.5 sampling and return half of the traces
-		traces, err := ds.getTraceSummariesData(
+		traces, sampling, err := ds.getTraceSummariesData(
 			context.Background(),
 			*makeQuery("", "2020-09-16T00:00:00Z", "2020-09-16T00:00:10Z"),
 			200,
@@ -147,6 +191,7 @@ func TestGetAnalytics(t *testing.T) {
 		)
 		require.NoError(t, err)
 		require.Equal(t, 200, len(traces))
+		require.Equal(t, 0.5, sampling)
 	})
 
 	t.Run("use approximate sampling", func(t *testing.T) {
@@ -162,13 +207,13 @@ func TestGetAnalytics(t *testing.T) {
 			makeTrace("2020-09-16T00:00:06Z", "1", 100),
 		)
 		settings := awsds.AWSDatasourceSettings{}
-		ds := NewDatasource(context.Background(), getXrayClientFactory(xrayMock), getAppSignalsClient, settings)
+		ds := NewDatasource(context.Background(), getXrayClientFactory(xrayMock), getAppSignalsClient, getCloudWatchClient, settings)
 		// first loop should return 600 traces which is more than 400
 		// sample those 600 to 300 (actual 299 due to probability)
 		// second loop returns 150 traces (using 0.5 sampling in the request)
 		// now we have 449 traces again and we have to sample again so we have 226 traces
 		seed = 42
-		traces, err := ds.getTraceSummariesData(
+		traces, sampling, err := ds.getTraceSummariesData(
 			context.Background(),
 			*makeQuery("some expression", "2020-09-16T00:00:00Z", "2020-09-16T00:00:10Z"),
 			400,
@@ -176,6 +221,7 @@ func TestGetAnalytics(t *testing.T) {
 		)
 		require.NoError(t, err)
 		require.Equal(t, 226, len(traces))
+		require.Equal(t, 0.25, sampling)
 	})
 }
 
@@ -0,0 +1,160 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+)
+
+// Maximum number of regions to get the configuration of at the same time.
+const maxConcurrentRegionRequests = 5
+
+type GetConfigurationQueryData struct {
+	Region string `json:"region"`
+	// Regions to get the configuration of, the Region of the query is used if empty.
+	Regions []string `json:"regions,omitempty"`
+}
+
+// regionConfiguration is the account level X-Ray configuration in a single region.
+type regionConfiguration struct {
+	encryption    *xraytypes.EncryptionConfig
+	indexingRules []xraytypes.IndexingRule
+	destination   *xray.GetTraceSegmentDestinationOutput
+}
+
+// getSingleConfiguration returns the encryption, indexing rules and trace segment destination of each region. The frame
+// is in the long format with one row for each region and indexing rule, the values that are interesting for alerting
+// are numbers and the rest are string fields, which alert rules use as labels.
+func (ds *Datasource) getSingleConfiguration(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetConfigurationQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	regions := queryData.Regions
+	if len(regions) == 0 {
+		regions = []string{queryData.Region}
+	}
+
+	log.DefaultLogger.Debug("getSingleConfiguration", "RefID", query.RefID, "regions", regions)
+
+	configurations := make([]regionConfiguration, len(regions))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentRegionRequests)
+	for i, region := range regions {
+		group.Go(func() error {
+			xrayClient, err := ds.getClient(groupCtx, pluginContext, RequestSettings{Region: region})
+			if err != nil {
+				return backend.PluginError(err)
+			}
+			configurations[i], err = getRegionConfiguration(groupCtx, xrayClient)
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frame := data.NewFrame(
+		"Configuration",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("KMS encrypted", nil, []float64{}),
+		data.NewField("Indexing sampling percentage", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Actual indexing sampling percentage", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("CloudWatch Logs destination", nil, []float64{}),
+		data.NewField("region", nil, []string{}),
+		data.NewField("encryptionType", nil, []string{}),
+		data.NewField("encryptionStatus", nil, []string{}),
+		data.NewField("keyId", nil, []string{}),
+		data.NewField("indexingRule", nil, []string{}),
+		data.NewField("destination", nil, []string{}),
+		data.NewField("destinationStatus", nil, []string{}),
+	)
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesLong}
+
+	for i, region := range regions {
+		if region == "" {
+			region = "default"
+		}
+		configuration := configurations[i]
+		encryption := Dereference(configuration.encryption)
+		destination := Dereference(configuration.destination)
+
+		kmsEncrypted := 0.0
+		if encryption.Type == xraytypes.EncryptionTypeKms {
+			kmsEncrypted = 1
+		}
+		cloudWatchLogs := 0.0
+		if destination.Destination == xraytypes.TraceSegmentDestinationCloudWatchLogs {
+			cloudWatchLogs = 1
+		}
+
+		// Indexing rules are only available with the CloudWatch Logs destination, the region still needs a row without them.
+		indexingRules := configuration.indexingRules
+		if len(indexingRules) == 0 {
+			indexingRules = []xraytypes.IndexingRule{{}}
+		}
+		for _, rule := range indexingRules {
+			var desired, actual *float64
+			if probabilistic, ok := rule.Rule.(*xraytypes.IndexingRuleValueMemberProbabilistic); ok {
+				desired = probabilistic.Value.DesiredSamplingPercentage
+				actual = probabilistic.Value.ActualSamplingPercentage
+			}
+			frame.AppendRow(
+				query.TimeRange.To,
+				kmsEncrypted,
+				desired,
+				actual,
+				cloudWatchLogs,
+				region,
+				string(encryption.Type),
+				string(encryption.Status),
+				Dereference(encryption.KeyId),
+				Dereference(rule.Name),
+				string(destination.Destination),
+				string(destination.Status),
+			)
+		}
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+func getRegionConfiguration(ctx context.Context, xrayClient XrayClient) (regionConfiguration, error) {
+	configuration := regionConfiguration{}
+
+	encryption, err := xrayClient.GetEncryptionConfig(ctx, &xray.GetEncryptionConfigInput{})
+	if err != nil {
+		return configuration, backend.DownstreamError(err)
+	}
+	configuration.encryption = encryption.EncryptionConfig
+
+	input := &xray.GetIndexingRulesInput{}
+	for {
+		output, err := xrayClient.GetIndexingRules(ctx, input)
+		if err != nil {
+			return configuration, backend.DownstreamError(err)
+		}
+		configuration.indexingRules = append(configuration.indexingRules, output.IndexingRules...)
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	configuration.destination, err = xrayClient.GetTraceSegmentDestination(ctx, &xray.GetTraceSegmentDestinationInput{})
+	if err != nil {
+		return configuration, backend.DownstreamError(err)
+	}
+	return configuration, nil
+}
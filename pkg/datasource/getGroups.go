@@ -11,8 +11,17 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
 )
 
+// Maximum number of groups to query at the same time when a query needs data of every group.
+const maxConcurrentGroupRequests = 5
+
+type GetGroupsQueryData struct {
+	Region string `json:"region"`
+}
+
 func (ds *Datasource) getGroups(rw http.ResponseWriter, req *http.Request) {
 	if req.Method != "GET" {
 		rw.WriteHeader(http.StatusMethodNotAllowed)
@@ -85,3 +94,64 @@ func getGroupsFromXray(ctx context.Context, xrayClient XrayClient) ([]xraytypes.
 	}
 	return groups, pagerError
 }
+
+// getSingleGroups returns a table of the groups with their configuration and the count of traces in each of them in
+// the time range.
+func (ds *Datasource) getSingleGroups(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetGroupsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleGroups", "RefID", query.RefID, "region", queryData.Region)
+
+	groups, err := getGroupsFromXray(ctx, xrayClient)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	counts := make([]int64, len(groups))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentGroupRequests)
+	for i, xrayGroup := range groups {
+		group.Go(func() error {
+			count, err := getTracesCount(groupCtx, xrayClient, query.TimeRange.From, query.TimeRange.To, xrayGroup.GroupName)
+			counts[i] = count
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frame := data.NewFrame(
+		"Groups",
+		data.NewField("Name", nil, []string{}),
+		data.NewField("ARN", nil, []string{}),
+		data.NewField("Filter expression", nil, []string{}),
+		data.NewField("Insights enabled", nil, []bool{}),
+		data.NewField("Notifications enabled", nil, []bool{}),
+		data.NewField("Trace count", nil, []int64{}),
+	)
+	for i, xrayGroup := range groups {
+		insightsConfiguration := Dereference(xrayGroup.InsightsConfiguration)
+		frame.AppendRow(
+			Dereference(xrayGroup.GroupName),
+			Dereference(xrayGroup.GroupARN),
+			Dereference(xrayGroup.FilterExpression),
+			Dereference(insightsConfiguration.InsightsEnabled),
+			Dereference(insightsConfiguration.NotificationsEnabled),
+			counts[i],
+		)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
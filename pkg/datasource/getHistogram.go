@@ -0,0 +1,219 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	HistogramResponseTime = "responseTime"
+	HistogramDuration     = "duration"
+
+	// Grafana heatmap panel reads frames of this type without having to bucket the values again.
+	frameTypeHeatmapCells data.FrameType = "heatmap-cells"
+)
+
+type GetHistogramQueryData struct {
+	Region string           `json:"region"`
+	Group  *xraytypes.Group `json:"group"`
+
+	// ServiceName and ServiceType select the service. When TargetServiceName is set the histogram is for the edge
+	// from the service to the target service instead. Empty types match any type.
+	ServiceName       string `json:"serviceName"`
+	ServiceType       string `json:"serviceType,omitempty"`
+	TargetServiceName string `json:"targetServiceName,omitempty"`
+	TargetServiceType string `json:"targetServiceType,omitempty"`
+
+	// Histogram is either responseTime (default) or duration. Duration histogram is only available for services.
+	Histogram string `json:"histogram,omitempty"`
+
+	// OverTime returns the response time histogram for each Resolution seconds of the time range as heatmap cells.
+	OverTime   bool  `json:"overTime,omitempty"`
+	Resolution int32 `json:"resolution,omitempty"`
+}
+
+// getSingleHistogram returns the response time or duration histogram of a service or an edge.
+func (ds *Datasource) getSingleHistogram(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetHistogramQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	if queryData.ServiceName == "" {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("service name is required for histogram query")))
+	}
+	if queryData.Histogram == "" {
+		queryData.Histogram = HistogramResponseTime
+	}
+	if queryData.Histogram != HistogramResponseTime && queryData.Histogram != HistogramDuration {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("unknown histogram: %s", queryData.Histogram)))
+	}
+	if queryData.Histogram == HistogramDuration && (queryData.OverTime || queryData.TargetServiceName != "") {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("duration histogram is only available for services and not over time")))
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleHistogram", "RefID", query.RefID, "service", queryData.ServiceName, "target", queryData.TargetServiceName)
+
+	if queryData.OverTime {
+		return getHistogramOverTime(ctx, xrayClient, query, queryData)
+	}
+
+	services, err := getServiceGraph(ctx, xrayClient, query.TimeRange.From, query.TimeRange.To, &GetServiceMapQueryData{Group: queryData.Group})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frame := data.NewFrame(
+		"histogram",
+		data.NewField("Value", nil, []float64{}).SetConfig(&data.FieldConfig{Unit: "s"}),
+		data.NewField("Count", nil, []int32{}),
+	)
+	for _, entry := range selectHistogram(services, queryData) {
+		frame.AppendRow(entry.Value, entry.Count)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// selectHistogram returns the histogram of the services or edges matching the query. The histograms are merged when
+// more of them match, for example when the same service is reported from multiple accounts.
+func selectHistogram(services []xraytypes.Service, queryData *GetHistogramQueryData) []xraytypes.HistogramEntry {
+	servicesByReferenceId := make(map[int32]xraytypes.Service)
+	for _, service := range services {
+		servicesByReferenceId[Dereference(service.ReferenceId)] = service
+	}
+
+	var histogram []xraytypes.HistogramEntry
+	for _, service := range services {
+		if !serviceMatches(service, queryData.ServiceName, queryData.ServiceType) {
+			continue
+		}
+		if queryData.TargetServiceName == "" {
+			if queryData.Histogram == HistogramDuration {
+				histogram = mergeHistograms(histogram, service.DurationHistogram)
+			} else {
+				histogram = mergeHistograms(histogram, service.ResponseTimeHistogram)
+			}
+			continue
+		}
+		for _, edge := range service.Edges {
+			target, ok := servicesByReferenceId[Dereference(edge.ReferenceId)]
+			if ok && serviceMatches(target, queryData.TargetServiceName, queryData.TargetServiceType) {
+				histogram = mergeHistograms(histogram, edge.ResponseTimeHistogram)
+			}
+		}
+	}
+	return histogram
+}
+
+func serviceMatches(service xraytypes.Service, name string, serviceType string) bool {
+	if serviceType != "" && Dereference(service.Type) != serviceType {
+		return false
+	}
+	return Dereference(service.Name) == name || slices.Contains(service.Names, name)
+}
+
+// mergeHistograms adds the counts of entries with the same value and returns the entries sorted by value.
+func mergeHistograms(histogram []xraytypes.HistogramEntry, entries []xraytypes.HistogramEntry) []xraytypes.HistogramEntry {
+	for _, entry := range entries {
+		index := slices.IndexFunc(histogram, func(existing xraytypes.HistogramEntry) bool {
+			return existing.Value == entry.Value
+		})
+		if index == -1 {
+			histogram = append(histogram, entry)
+			continue
+		}
+		histogram[index].Count += entry.Count
+	}
+	slices.SortFunc(histogram, func(a, b xraytypes.HistogramEntry) int {
+		if a.Value < b.Value {
+			return -1
+		}
+		if a.Value > b.Value {
+			return 1
+		}
+		return 0
+	})
+	return histogram
+}
+
+// getHistogramOverTime returns response time histograms from GetTimeSeriesServiceStatistics API as heatmap cells, one
+// row for each histogram entry in each time bucket.
+func getHistogramOverTime(ctx context.Context, xrayClient XrayClient, query backend.DataQuery, queryData *GetHistogramQueryData) backend.DataResponse {
+	resolution := int32(60)
+	if queryData.Resolution != 0 {
+		resolution = queryData.Resolution
+	}
+
+	request := &xray.GetTimeSeriesServiceStatisticsInput{
+		StartTime:                &query.TimeRange.From,
+		EndTime:                  &query.TimeRange.To,
+		EntitySelectorExpression: aws.String(histogramEntitySelector(queryData)),
+		Period:                   &resolution,
+	}
+	if queryData.Group != nil {
+		request.GroupName = queryData.Group.GroupName
+	}
+
+	frame := data.NewFrame(
+		"histogram",
+		data.NewField("xMin", nil, []time.Time{}),
+		data.NewField("yMin", nil, []float64{}).SetConfig(&data.FieldConfig{Unit: "s"}),
+		data.NewField("count", nil, []int32{}),
+	)
+	frame.Meta = &data.FrameMeta{Type: frameTypeHeatmapCells}
+
+	pager := xray.NewGetTimeSeriesServiceStatisticsPaginator(xrayClient, request)
+	var pagerError error
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			pagerError = err
+			break
+		}
+		for _, statistics := range page.TimeSeriesServiceStatistics {
+			if statistics.Timestamp == nil {
+				continue
+			}
+			for _, entry := range mergeHistograms(nil, statistics.ResponseTimeHistogram) {
+				frame.AppendRow(*statistics.Timestamp, entry.Value, entry.Count)
+			}
+		}
+	}
+
+	if pagerError != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(pagerError))
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// histogramEntitySelector returns the X-Ray entity selector expression for the service or edge from the query.
+func histogramEntitySelector(queryData *GetHistogramQueryData) string {
+	source := serviceSelector(queryData.ServiceName, queryData.ServiceType, "")
+	if queryData.TargetServiceName == "" {
+		return fmt.Sprintf("service(%s)", source)
+	}
+	return fmt.Sprintf("edge(%s, %s)", source, serviceSelector(queryData.TargetServiceName, queryData.TargetServiceType, ""))
+}
@@ -0,0 +1,21 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramEntitySelector(t *testing.T) {
+	require.Equal(t, `service(id(name: "A"))`, histogramEntitySelector(&GetHistogramQueryData{ServiceName: "A"}))
+	require.Equal(
+		t,
+		`edge(id(name: "A", type: "AWS::Lambda"), id(name: "table \"x\"", type: "AWS::DynamoDB::Table"))`,
+		histogramEntitySelector(&GetHistogramQueryData{
+			ServiceName:       "A",
+			ServiceType:       "AWS::Lambda",
+			TargetServiceName: `table "x"`,
+			TargetServiceType: "AWS::DynamoDB::Table",
+		}),
+	)
+}
@@ -0,0 +1,243 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// GetInsightImpactGraph does not accept a longer time range.
+const maxInsightImpactGraphDuration = 6 * time.Hour
+
+type GetInsightDetailsQueryData struct {
+	InsightId string `json:"insightId"`
+	Region    string `json:"region"`
+}
+
+// getSingleInsightDetails returns the details of a single insight: the timeline of its events, the request impact
+// statistics and the impact graph as node graph frames.
+func (ds *Datasource) getSingleInsightDetails(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetInsightDetailsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	if queryData.InsightId == "" {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("insight id is required for insight details query")))
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleInsightDetails", "RefID", query.RefID, "insightId", queryData.InsightId)
+
+	insightResponse, err := xrayClient.GetInsight(ctx, &xray.GetInsightInput{InsightId: &queryData.InsightId})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+	}
+	insight := insightResponse.Insight
+	if insight == nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("insight %s not found", queryData.InsightId)))
+	}
+
+	eventsFrame, err := getInsightEventsFrame(ctx, xrayClient, queryData.InsightId)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	impactGraph, err := getInsightImpactGraph(ctx, xrayClient, insight)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frames := []*data.Frame{eventsFrame, insightImpactFrame(insight)}
+	frames = append(frames, insightImpactGraphFrames(insight, impactGraph)...)
+	return backend.DataResponse{
+		Frames: frames,
+	}
+}
+
+// getInsightEventsFrame returns all the events of the insight ordered by time.
+func getInsightEventsFrame(ctx context.Context, xrayClient XrayClient, insightId string) (*data.Frame, error) {
+	frame := data.NewFrame(
+		"InsightEvents",
+		data.NewField("Time", nil, []*time.Time{}),
+		data.NewField("Summary", nil, []string{}),
+		data.NewField("Client fault count", nil, []*int64{}),
+		data.NewField("Client ok count", nil, []*int64{}),
+		data.NewField("Client total count", nil, []*int64{}),
+		data.NewField("Root cause fault count", nil, []*int64{}),
+		data.NewField("Root cause ok count", nil, []*int64{}),
+		data.NewField("Root cause total count", nil, []*int64{}),
+		data.NewField("Anomalous services", nil, []json.RawMessage{}),
+	)
+
+	var events []xraytypes.InsightEvent
+	pager := xray.NewGetInsightEventsPaginator(xrayClient, &xray.GetInsightEventsInput{InsightId: &insightId})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		events = append(events, page.InsightEvents...)
+	}
+	slices.SortStableFunc(events, func(a, b xraytypes.InsightEvent) int {
+		return Dereference(a.EventTime).Compare(Dereference(b.EventTime))
+	})
+
+	for _, event := range events {
+		anomalousServices, err := anomalousServicesList(event.TopAnomalousServices)
+		if err != nil {
+			return nil, backend.PluginError(err)
+		}
+		client := Dereference(event.ClientRequestImpactStatistics)
+		rootCause := Dereference(event.RootCauseServiceRequestImpactStatistics)
+		frame.AppendRow(
+			event.EventTime,
+			Dereference(event.Summary),
+			client.FaultCount,
+			client.OkCount,
+			client.TotalCount,
+			rootCause.FaultCount,
+			rootCause.OkCount,
+			rootCause.TotalCount,
+			anomalousServices,
+		)
+	}
+	return frame, nil
+}
+
+// insightImpactFrame returns the request impact statistics of the clients and of the root cause service over the whole
+// insight.
+func insightImpactFrame(insight *xraytypes.Insight) *data.Frame {
+	frame := data.NewFrame(
+		"InsightImpact",
+		data.NewField("Requests", nil, []string{}),
+		data.NewField("Fault count", nil, []*int64{}),
+		data.NewField("Ok count", nil, []*int64{}),
+		data.NewField("Total count", nil, []*int64{}),
+	)
+	client := Dereference(insight.ClientRequestImpactStatistics)
+	rootCause := Dereference(insight.RootCauseServiceRequestImpactStatistics)
+	frame.AppendRow("Client requests", client.FaultCount, client.OkCount, client.TotalCount)
+	frame.AppendRow(fmt.Sprintf("Root cause service %s", serviceIdString(insight.RootCauseServiceId)), rootCause.FaultCount, rootCause.OkCount, rootCause.TotalCount)
+	return frame
+}
+
+// getInsightImpactGraph returns the services impacted by the insight. The graph is requested for the duration of the
+// insight, capped at the longest time range the API accepts.
+func getInsightImpactGraph(ctx context.Context, xrayClient XrayClient, insight *xraytypes.Insight) ([]xraytypes.InsightImpactGraphService, error) {
+	startTime := Dereference(insight.StartTime)
+	endTime := time.Now()
+	if insight.EndTime != nil {
+		endTime = *insight.EndTime
+	}
+	if endTime.Sub(startTime) > maxInsightImpactGraphDuration {
+		endTime = startTime.Add(maxInsightImpactGraphDuration)
+	}
+
+	input := &xray.GetInsightImpactGraphInput{
+		InsightId: insight.InsightId,
+		StartTime: &startTime,
+		EndTime:   &endTime,
+	}
+	var services []xraytypes.InsightImpactGraphService
+	for {
+		output, err := xrayClient.GetInsightImpactGraph(ctx, input)
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		services = append(services, output.Services...)
+		if output.NextToken == nil || *output.NextToken == "" {
+			return services, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+// insightImpactGraphFrames returns nodes and edges frames in the format expected by the Grafana node graph
+// visualisation. The arc of each node shows whether it is the root cause, one of the anomalous services or only
+// impacted.
+func insightImpactGraphFrames(insight *xraytypes.Insight, services []xraytypes.InsightImpactGraphService) []*data.Frame {
+	nodesFrame := data.NewFrame(
+		"nodes",
+		data.NewField("id", nil, []string{}),
+		data.NewField("title", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Name"}),
+		data.NewField("subtitle", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Type"}),
+		data.NewField("detail__accountId", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Account ID"}),
+		data.NewField("arc__rootCause", nil, []float64{}).SetConfig(arcConfig("Root cause", "red")),
+		data.NewField("arc__anomalous", nil, []float64{}).SetConfig(arcConfig("Anomalous", "orange")),
+		data.NewField("arc__impacted", nil, []float64{}).SetConfig(arcConfig("Impacted", "semi-dark-yellow")),
+	)
+	nodesFrame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	edgesFrame := data.NewFrame(
+		"edges",
+		data.NewField("id", nil, []string{}),
+		data.NewField("source", nil, []string{}),
+		data.NewField("target", nil, []string{}),
+	)
+	edgesFrame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+
+	referenceIds := make(map[int32]bool)
+	for _, service := range services {
+		referenceIds[Dereference(service.ReferenceId)] = true
+	}
+
+	for _, service := range services {
+		serviceId := xraytypes.ServiceId{AccountId: service.AccountId, Name: service.Name, Names: service.Names, Type: service.Type}
+		var rootCause, anomalous, impacted float64
+		switch {
+		case insight.RootCauseServiceId != nil && serviceIdEqual(*insight.RootCauseServiceId, serviceId):
+			rootCause = 1
+		case slices.ContainsFunc(insight.TopAnomalousServices, func(anomalousService xraytypes.AnomalousService) bool {
+			return anomalousService.ServiceId != nil && serviceIdEqual(*anomalousService.ServiceId, serviceId)
+		}):
+			anomalous = 1
+		default:
+			impacted = 1
+		}
+
+		id := strconv.FormatInt(int64(Dereference(service.ReferenceId)), 10)
+		nodesFrame.AppendRow(id, Dereference(service.Name), Dereference(service.Type), Dereference(service.AccountId), rootCause, anomalous, impacted)
+
+		for _, edge := range service.Edges {
+			// Same as in the service map, targets may be missing from the response.
+			if !referenceIds[Dereference(edge.ReferenceId)] {
+				continue
+			}
+			target := strconv.FormatInt(int64(Dereference(edge.ReferenceId)), 10)
+			edgesFrame.AppendRow(fmt.Sprintf("%s__%s", id, target), id, target)
+		}
+	}
+
+	return []*data.Frame{nodesFrame, edgesFrame}
+}
+
+func serviceIdEqual(a xraytypes.ServiceId, b xraytypes.ServiceId) bool {
+	if Dereference(a.Name) != Dereference(b.Name) || Dereference(a.Type) != Dereference(b.Type) {
+		return false
+	}
+	// Account is only known in cross-account setups.
+	return a.AccountId == nil || b.AccountId == nil || *a.AccountId == *b.AccountId
+}
+
+// serviceIdString returns the service in the "name (type)" form used in insight tables.
+func serviceIdString(serviceId *xraytypes.ServiceId) string {
+	if serviceId == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", Dereference(serviceId.Name), Dereference(serviceId.Type))
+}
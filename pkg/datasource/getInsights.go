@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -24,21 +25,11 @@ type GetInsightsQueryData struct {
 }
 
 func (ds *Datasource) getSingleInsight(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
-	queryData := &GetInsightsQueryData{}
-	err := json.Unmarshal(query.JSON, queryData)
-	if err != nil {
-		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
-	}
-
-	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	insights, notices, err := ds.getInsightSummaries(ctx, query, pluginContext)
 	if err != nil {
-		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+		return backend.ErrorResponseWithErrorSource(err)
 	}
 
-	var states = []string{strings.ToUpper(queryData.State)}
-
-	log.DefaultLogger.Debug("getSingleInsight", "states", states, "group", queryData.Group)
-
 	responseDataFrame := data.NewFrame(
 		"InsightSummaries",
 		data.NewField("InsightId", nil, []*string{}),
@@ -47,40 +38,165 @@ func (ds *Datasource) getSingleInsight(ctx context.Context, query backend.DataQu
 		data.NewField("Categories", nil, []string{}),
 		data.NewField("Duration", nil, []int64{}),
 		data.NewField("Root cause service", nil, []string{}),
-		data.NewField("Anomalous services", nil, []string{}),
+		data.NewField("Anomalous services", nil, []json.RawMessage{}),
 		data.NewField("Group", nil, []*string{}),
 		data.NewField("Start time", nil, []*time.Time{}),
 	)
+	responseDataFrame.Meta = &data.FrameMeta{Notices: notices}
+
+	for _, insight := range insights {
+		// Insights that are still being analyzed may not have a root cause yet.
+		rootCauseService := serviceIdString(insight.RootCauseServiceId)
+		anomalousServices, err := anomalousServicesList(insight.TopAnomalousServices)
+		if err != nil {
+			return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+		}
+		responseDataFrame.AppendRow(
+			insight.InsightId,
+			getDescription(insight, rootCauseService),
+			cases.Title(language.Und).String(strings.ToLower(string(insight.State))),
+			getCategories(insight.Categories),
+			getDuration(insight.StartTime, insight.EndTime),
+			rootCauseService,
+			anomalousServices,
+			insight.GroupName,
+			insight.StartTime,
+		)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{responseDataFrame},
+	}
+}
+
+// getSingleInsightAnnotations returns the same insights as getSingleInsight in the format of Grafana annotations, so
+// they can be shown as regions over other panels. Insights which are still active end at the end of the time range.
+func (ds *Datasource) getSingleInsightAnnotations(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	insights, notices, err := ds.getInsightSummaries(ctx, query, pluginContext)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frame := data.NewFrame(
+		"InsightAnnotations",
+		data.NewField("time", nil, []*time.Time{}),
+		data.NewField("timeEnd", nil, []*time.Time{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("text", nil, []string{}),
+		// Grafana splits the tags by comma
+		data.NewField("tags", nil, []string{}),
+	)
+	frame.Meta = &data.FrameMeta{Notices: notices}
+
+	for _, insight := range insights {
+		rootCauseService := serviceIdString(insight.RootCauseServiceId)
+		endTime := insight.EndTime
+		if endTime == nil {
+			endTime = &query.TimeRange.To
+		}
+
+		title := "X-Ray insight"
+		if rootCauseService != "" {
+			title = fmt.Sprintf("X-Ray insight in %s", rootCauseService)
+		}
+
+		var tags []string
+		for _, category := range insight.Categories {
+			tags = append(tags, cases.Title(language.Und).String(strings.ToLower(string(category))))
+		}
+		if insight.GroupName != nil {
+			tags = append(tags, *insight.GroupName)
+		}
+		if rootCauseService != "" {
+			tags = append(tags, rootCauseService)
+		}
+
+		frame.AppendRow(insight.StartTime, endTime, title, getDescription(insight, rootCauseService), strings.Join(tags, ","))
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// getInsightSummaries returns the insights matching the state and group of the query. Insights from all the groups
+// are returned when the group is All, together with a notice for each group that failed.
+func (ds *Datasource) getInsightSummaries(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) ([]xraytypes.InsightSummary, []data.Notice, error) {
+	queryData := &GetInsightsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return nil, nil, backend.PluginError(err)
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return nil, nil, backend.PluginError(err)
+	}
+
+	var states = []string{strings.ToUpper(queryData.State)}
+
+	log.DefaultLogger.Debug("getInsightSummaries", "states", states, "group", queryData.Group)
 
 	if queryData.State == "All" || len(queryData.State) == 0 {
 		states = nil
 	}
 
-	if Dereference(queryData.Group.GroupName) == "All" {
+	if queryData.Group == nil || Dereference(queryData.Group.GroupName) == "All" {
 		groups, err := getGroupsFromXray(ctx, xrayClient)
-
 		if err != nil {
-			return backend.ErrorResponseWithErrorSource(err)
+			return nil, nil, err
 		}
+		return getInsightSummariesOfGroups(ctx, xrayClient, query, states, groups)
+	}
 
-		for _, group := range groups {
-			err = getInsightSummary(ctx, xrayClient, query, states, group.GroupName, responseDataFrame)
+	insights, err := getInsightSummary(ctx, xrayClient, query, states, queryData.Group.GroupName)
+	return insights, nil, err
+}
 
-			if err != nil {
-				return backend.ErrorResponseWithErrorSource(err)
-			}
-		}
+// getInsightSummariesOfGroups gets the insights of the groups concurrently. An insight can be in more groups, it is
+// returned only once with the first of them. Failed groups are reported as notices so the other groups can still be
+// shown, the whole query fails only if every group fails.
+func getInsightSummariesOfGroups(ctx context.Context, xrayClient XrayClient, query backend.DataQuery, states []string, groups []xraytypes.GroupSummary) ([]xraytypes.InsightSummary, []data.Notice, error) {
+	results := make([][]xraytypes.InsightSummary, len(groups))
+	errs := make([]error, len(groups))
+	var group errgroup.Group
+	group.SetLimit(maxConcurrentGroupRequests)
+	for i, xrayGroup := range groups {
+		group.Go(func() error {
+			results[i], errs[i] = getInsightSummary(ctx, xrayClient, query, states, xrayGroup.GroupName)
+			return nil
+		})
+	}
+	_ = group.Wait()
 
-	} else {
-		err = getInsightSummary(ctx, xrayClient, query, states, queryData.Group.GroupName, responseDataFrame)
-		if err != nil {
-			return backend.ErrorResponseWithErrorSource(err)
+	var insights []xraytypes.InsightSummary
+	var notices []data.Notice
+	seen := make(map[string]bool)
+	failed := 0
+	for i, xrayGroup := range groups {
+		if errs[i] != nil {
+			failed++
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Failed to get insights of group %s: %s", Dereference(xrayGroup.GroupName), errs[i]),
+			})
+			continue
+		}
+		for _, insight := range results[i] {
+			if insight.InsightId != nil {
+				if seen[*insight.InsightId] {
+					continue
+				}
+				seen[*insight.InsightId] = true
+			}
+			insights = append(insights, insight)
 		}
 	}
 
-	return backend.DataResponse{
-		Frames: []*data.Frame{responseDataFrame},
+	if failed > 0 && failed == len(groups) {
+		return nil, nil, errs[0]
 	}
+	return insights, notices, nil
 }
 
 func toInsightStates(states []string) []xraytypes.InsightState {
@@ -101,36 +217,36 @@ func Dereference[T any](p *T) T {
 	return out
 }
 
-func getInsightSummary(ctx context.Context, xrayClient XrayClient, query backend.DataQuery, states []string, groupName *string, responseDataFrame *data.Frame) error {
-	insightsResponse, err := xrayClient.GetInsightSummaries(ctx, &xray.GetInsightSummariesInput{
+func getInsightSummary(ctx context.Context, xrayClient XrayClient, query backend.DataQuery, states []string, groupName *string) ([]xraytypes.InsightSummary, error) {
+	pager := xray.NewGetInsightSummariesPaginator(xrayClient, &xray.GetInsightSummariesInput{
 		StartTime: &query.TimeRange.From,
 		EndTime:   &query.TimeRange.To,
 		States:    toInsightStates(states),
 		GroupName: groupName,
 	})
 
-	if err != nil {
-		log.DefaultLogger.Debug("GetInsightSummaries", "error", err)
-		return backend.DownstreamError(err)
+	var insights []xraytypes.InsightSummary
+	for pager.HasMorePages() {
+		insightsResponse, err := pager.NextPage(ctx)
+		if err != nil {
+			log.DefaultLogger.Debug("GetInsightSummaries", "error", err)
+			return nil, backend.DownstreamError(err)
+		}
+		insights = append(insights, insightsResponse.InsightSummaries...)
 	}
+	return insights, nil
+}
 
-	for _, insight := range insightsResponse.InsightSummaries {
-
-		rootCauseService := fmt.Sprintf("%s (%s)", Dereference(insight.RootCauseServiceId.Name), Dereference(insight.RootCauseServiceId.Type))
-		anomalousService := fmt.Sprintf("%s (%s)", Dereference(insight.TopAnomalousServices[0].ServiceId.Name), Dereference(insight.TopAnomalousServices[0].ServiceId.Type))
-		responseDataFrame.AppendRow(
-			insight.InsightId,
-			getDescription(insight, rootCauseService),
-			cases.Title(language.Und).String(strings.ToLower(string(insight.State))),
-			getCategories(insight.Categories),
-			getDuration(insight.StartTime, insight.EndTime),
-			rootCauseService,
-			anomalousService,
-			insight.GroupName,
-			insight.StartTime,
-		)
+// anomalousServicesList returns the services as a JSON list, which tables show as a list in a single cell.
+func anomalousServicesList(services []xraytypes.AnomalousService) (json.RawMessage, error) {
+	out := make([]string, 0, len(services))
+	for _, service := range services {
+		if service.ServiceId == nil {
+			continue
+		}
+		out = append(out, serviceIdString(service.ServiceId))
 	}
-	return nil
+	return json.Marshal(out)
 }
 
 func getCategories(categories []xraytypes.InsightCategory) string {
@@ -146,13 +262,23 @@ func getDescription(insight xraytypes.InsightSummary, rootCauseService string) s
 		return Dereference(insight.Summary)
 	}
 
-	description := strings.Split(Dereference(insight.Summary), ".")[1]
+	// The first sentence of closed insight summaries only repeats the time range.
+	var description string
+	if sentences := strings.Split(Dereference(insight.Summary), "."); len(sentences) > 1 {
+		description = strings.TrimSpace(sentences[1])
+	}
 
 	if description == "" {
+		if rootCauseService == "" {
+			return Dereference(insight.Summary)
+		}
+		if len(insight.Categories) == 0 {
+			return fmt.Sprintf("There were failures in %s", rootCauseService)
+		}
 		return fmt.Sprintf("There were failures in %s due to %s", rootCauseService, insight.Categories[0])
 	}
 
-	return strings.TrimSpace(description) + "."
+	return description + "."
 }
 
 func getDuration(startTime *time.Time, endTime *time.Time) int64 {
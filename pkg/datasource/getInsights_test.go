@@ -0,0 +1,63 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+// insightsClientMock returns the insights by group name and fails for groups without any.
+type insightsClientMock struct {
+	XrayClient
+	insights map[string][]xraytypes.InsightSummary
+}
+
+func (client *insightsClientMock) GetInsightSummaries(_ context.Context, input *xray.GetInsightSummariesInput, _ ...func(*xray.Options)) (*xray.GetInsightSummariesOutput, error) {
+	insights, ok := client.insights[*input.GroupName]
+	if !ok {
+		return nil, errors.New("access denied")
+	}
+	return &xray.GetInsightSummariesOutput{InsightSummaries: insights}, nil
+}
+
+func TestGetInsightSummariesOfGroups(t *testing.T) {
+	client := &insightsClientMock{
+		insights: map[string][]xraytypes.InsightSummary{
+			"Default": {
+				{InsightId: aws.String("1"), GroupName: aws.String("Default")},
+				{InsightId: aws.String("2"), GroupName: aws.String("Default")},
+			},
+			"Frontend": {
+				{InsightId: aws.String("2"), GroupName: aws.String("Frontend")},
+				{InsightId: aws.String("3"), GroupName: aws.String("Frontend")},
+			},
+		},
+	}
+
+	t.Run("deduplicates insights and reports failed groups", func(t *testing.T) {
+		groups := []xraytypes.GroupSummary{{GroupName: aws.String("Default")}, {GroupName: aws.String("Failing")}, {GroupName: aws.String("Frontend")}}
+		insights, notices, err := getInsightSummariesOfGroups(context.Background(), client, backend.DataQuery{}, nil, groups)
+		require.NoError(t, err)
+
+		require.Len(t, insights, 3)
+		require.Equal(t, "1", *insights[0].InsightId)
+		require.Equal(t, "2", *insights[1].InsightId)
+		require.Equal(t, "Default", *insights[1].GroupName)
+		require.Equal(t, "3", *insights[2].InsightId)
+
+		require.Len(t, notices, 1)
+		require.Equal(t, "Failed to get insights of group Failing: access denied", notices[0].Text)
+	})
+
+	t.Run("fails when every group fails", func(t *testing.T) {
+		groups := []xraytypes.GroupSummary{{GroupName: aws.String("Failing")}, {GroupName: aws.String("Other")}}
+		_, _, err := getInsightSummariesOfGroups(context.Background(), client, backend.DataQuery{}, nil, groups)
+		require.Error(t, err)
+	})
+}
@@ -17,7 +17,7 @@ func TestGetRegions(t *testing.T) {
 	// We're just using the default list in the frontend.
 	t.Skip()
 	t.Run("when passed a get request it returns a list of regions from aws from all supported clouds", func(t *testing.T) {
-		ds := datasource.NewDatasource(context.Background(), xrayClientFactory, appSignalsClientFactory, awsds.AWSDatasourceSettings{})
+		ds := datasource.NewDatasource(context.Background(), xrayClientFactory, appSignalsClientFactory, cloudWatchClientFactory, awsds.AWSDatasourceSettings{})
 		req := httptest.NewRequest("GET", "http://example.com/regions", nil)
 		w := httptest.NewRecorder()
 		ds.GetRegions(w, req)
@@ -0,0 +1,156 @@
+package datasource
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type GetSamplingQueryData struct {
+	Region string `json:"region"`
+	// RuleName selects a single sampling rule, all the rules are returned if not set.
+	RuleName string `json:"ruleName,omitempty"`
+}
+
+// getSingleSamplingRules returns a table of the sampling rules ordered by priority, which is the order X-Ray matches
+// them in.
+func (ds *Datasource) getSingleSamplingRules(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetSamplingQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleSamplingRules", "RefID", query.RefID, "ruleName", queryData.RuleName)
+
+	var rules []xraytypes.SamplingRule
+	pager := xray.NewGetSamplingRulesPaginator(xrayClient, &xray.GetSamplingRulesInput{})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+		}
+		for _, record := range page.SamplingRuleRecords {
+			if record.SamplingRule == nil {
+				continue
+			}
+			if queryData.RuleName != "" && Dereference(record.SamplingRule.RuleName) != queryData.RuleName {
+				continue
+			}
+			rules = append(rules, *record.SamplingRule)
+		}
+	}
+	slices.SortStableFunc(rules, func(a, b xraytypes.SamplingRule) int {
+		return cmp.Compare(Dereference(a.Priority), Dereference(b.Priority))
+	})
+
+	frame := data.NewFrame(
+		"SamplingRules",
+		data.NewField("Name", nil, []string{}),
+		data.NewField("Priority", nil, []int32{}),
+		data.NewField("Fixed rate", nil, []float64{}).SetConfig(&data.FieldConfig{Unit: "percentunit"}),
+		data.NewField("Reservoir size", nil, []int32{}),
+		data.NewField("Service name", nil, []string{}),
+		data.NewField("Service type", nil, []string{}),
+		data.NewField("Host", nil, []string{}),
+		data.NewField("HTTP method", nil, []string{}),
+		data.NewField("URL path", nil, []string{}),
+		data.NewField("Resource ARN", nil, []string{}),
+		data.NewField("Attributes", nil, []json.RawMessage{}),
+		data.NewField("ARN", nil, []string{}),
+	)
+	for _, rule := range rules {
+		attributes, err := json.Marshal(rule.Attributes)
+		if err != nil {
+			return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+		}
+		frame.AppendRow(
+			Dereference(rule.RuleName),
+			Dereference(rule.Priority),
+			rule.FixedRate,
+			rule.ReservoirSize,
+			Dereference(rule.ServiceName),
+			Dereference(rule.ServiceType),
+			Dereference(rule.Host),
+			Dereference(rule.HTTPMethod),
+			Dereference(rule.URLPath),
+			Dereference(rule.ResourceARN),
+			json.RawMessage(attributes),
+			Dereference(rule.RuleARN),
+		)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// getSingleSamplingStatistics returns the request, sampled and borrowed counts of each sampling rule in the time range.
+// X-Ray keeps the statistics only for the last hour. The frame is a long time series with the rule name as a label, so
+// it works as a table and as a graph with a series for each rule.
+func (ds *Datasource) getSingleSamplingStatistics(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetSamplingQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleSamplingStatistics", "RefID", query.RefID, "ruleName", queryData.RuleName)
+
+	var summaries []xraytypes.SamplingStatisticSummary
+	pager := xray.NewGetSamplingStatisticSummariesPaginator(xrayClient, &xray.GetSamplingStatisticSummariesInput{})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+		}
+		for _, summary := range page.SamplingStatisticSummaries {
+			if summary.Timestamp == nil || summary.Timestamp.Before(query.TimeRange.From) || summary.Timestamp.After(query.TimeRange.To) {
+				continue
+			}
+			if queryData.RuleName != "" && Dereference(summary.RuleName) != queryData.RuleName {
+				continue
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+	// Long frames need to be sorted by time
+	slices.SortStableFunc(summaries, func(a, b xraytypes.SamplingStatisticSummary) int {
+		return cmp.Or(a.Timestamp.Compare(*b.Timestamp), cmp.Compare(Dereference(a.RuleName), Dereference(b.RuleName)))
+	})
+
+	frame := data.NewFrame(
+		"SamplingStatistics",
+		data.NewField("Time", nil, []time.Time{}),
+		data.NewField("Rule", nil, []string{}),
+		data.NewField("Request count", nil, []int32{}),
+		data.NewField("Sampled count", nil, []int32{}),
+		data.NewField("Borrow count", nil, []int32{}),
+	)
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesLong}
+	for _, summary := range summaries {
+		frame.AppendRow(*summary.Timestamp, Dereference(summary.RuleName), summary.RequestCount, summary.SampledCount, summary.BorrowCount)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
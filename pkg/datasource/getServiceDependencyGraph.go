@@ -0,0 +1,442 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultServiceDependencyGraphDepth = 2
+	// Every hop needs a few requests for each service, so the depth and the number of nodes are limited to keep the
+	// query fast.
+	maxServiceDependencyGraphDepth = 5
+	maxServiceDependencyGraphNodes = 100
+
+	maxConcurrentDependencyGraphRequests = 5
+
+	// Type of the key attributes of the services instrumented with Application Signals. Only these services have
+	// dependencies, dependents and operations of their own.
+	appSignalsServiceType = "Service"
+)
+
+type GetServiceDependencyGraphQueryData struct {
+	Region        string `json:"region,omitempty"`
+	ServiceString string `json:"serviceString,omitempty"`
+	// Depth is the number of hops to walk from the service, 2 if not set.
+	Depth int `json:"depth,omitempty"`
+	// Direction is FocusDirectionDownstream to only walk the dependencies, FocusDirectionUpstream to only walk the
+	// dependents, or empty to walk both.
+	Direction string `json:"direction,omitempty"`
+}
+
+// dependencyGraphNode is a service or a resource in the dependency graph, identified by its key attributes.
+type dependencyGraphNode struct {
+	id            string
+	keyAttributes map[string]string
+	// metrics are the metrics of the operations of the node, only services instrumented with Application Signals
+	// have them.
+	metrics []appSignalsTypes.MetricReference
+}
+
+type dependencyGraphEdge struct {
+	source     string
+	target     string
+	metrics    []appSignalsTypes.MetricReference
+	metricKeys map[string]bool
+}
+
+// getSingleServiceDependencyGraph walks the dependencies and dependents of a service in Application Signals and
+// returns them as node graph frames in the same format as the X-Ray service map. The statistics of the nodes and
+// edges come from the CloudWatch metrics Application Signals references, so the graph also works for services that
+// don't send traces to X-Ray.
+func (ds *Datasource) getSingleServiceDependencyGraph(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetServiceDependencyGraphQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	if len(queryData.ServiceString) == 0 {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("Service not set on query"))
+	}
+	depth := queryData.Depth
+	if depth <= 0 {
+		depth = defaultServiceDependencyGraphDepth
+	}
+	if depth > maxServiceDependencyGraphDepth {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("depth can be at most %d", maxServiceDependencyGraphDepth))
+	}
+	if err := validateFocusDirection(queryData.Direction); err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+	}
+
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+	cloudWatchClient, err := ds.getCloudWatchClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	serviceMap := map[string]string{}
+	err = json.Unmarshal([]byte(queryData.ServiceString), &serviceMap)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleServiceDependencyGraph", "RefID", query.RefID, "service", queryData.ServiceString, "depth", depth)
+
+	nodes, edges, truncated, err := walkServiceDependencyGraph(ctx, appSignalsClient, serviceMap, query.TimeRange, depth, queryData.Direction, maxServiceDependencyGraphNodes)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frames, err := serviceDependencyGraphFrames(ctx, cloudWatchClient, nodes, edges, query.TimeRange)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+	if truncated {
+		frames[0].Meta.Notices = append(frames[0].Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("The graph is limited to %d nodes, reduce the depth or choose a direction to see all of them.", maxServiceDependencyGraphNodes),
+		})
+	}
+	return backend.DataResponse{
+		Frames: frames,
+	}
+}
+
+// serviceNeighbours are the metrics of the operations of a service and the calls between it and other services and
+// resources.
+type serviceNeighbours struct {
+	metrics      []appSignalsTypes.MetricReference
+	dependencies []appSignalsTypes.ServiceDependency
+	dependents   []appSignalsTypes.ServiceDependent
+}
+
+// walkServiceDependencyGraph does a breadth first search from the service and returns the nodes and edges it finds
+// within depth hops, in the order they were found. The services of each hop are requested concurrently. Once there
+// are maxNodes nodes no more are added and truncated is true.
+func walkServiceDependencyGraph(ctx context.Context, appSignalsClient AppSignalsClient, serviceMap map[string]string, timeRange backend.TimeRange, depth int, direction string, maxNodes int) (nodes []*dependencyGraphNode, edges []*dependencyGraphEdge, truncated bool, err error) {
+	nodesById := make(map[string]*dependencyGraphNode)
+	addNode := func(keyAttributes map[string]string) (*dependencyGraphNode, bool) {
+		id := Dereference(keyAttributesString(keyAttributes))
+		if node, ok := nodesById[id]; ok {
+			return node, false
+		}
+		if len(nodes) >= maxNodes {
+			truncated = true
+			return nil, false
+		}
+		node := &dependencyGraphNode{id: id, keyAttributes: keyAttributes}
+		nodesById[id] = node
+		nodes = append(nodes, node)
+		return node, true
+	}
+
+	edgesById := make(map[string]*dependencyGraphEdge)
+	addEdge := func(source string, target string, metrics []appSignalsTypes.MetricReference) {
+		id := fmt.Sprintf("%s__%s", source, target)
+		edge, ok := edgesById[id]
+		if !ok {
+			edge = &dependencyGraphEdge{source: source, target: target, metricKeys: make(map[string]bool)}
+			edgesById[id] = edge
+			edges = append(edges, edge)
+		}
+		// The same edge is found from both of its ends when walking in both directions, the metrics must be counted
+		// only once.
+		for _, metric := range metrics {
+			key := metricReferenceKey(metric)
+			if !edge.metricKeys[key] {
+				edge.metricKeys[key] = true
+				edge.metrics = append(edge.metrics, metric)
+			}
+		}
+	}
+
+	root, _ := addNode(serviceMap)
+	level := []*dependencyGraphNode{root}
+	for hop := 0; len(level) > 0; hop++ {
+		var services []*dependencyGraphNode
+		for _, node := range level {
+			if node.keyAttributes["Type"] == appSignalsServiceType {
+				services = append(services, node)
+			}
+		}
+
+		results := make([]serviceNeighbours, len(services))
+		group, groupCtx := errgroup.WithContext(ctx)
+		group.SetLimit(maxConcurrentDependencyGraphRequests)
+		for i, node := range services {
+			group.Go(func() error {
+				neighbours, err := getServiceNeighbours(groupCtx, appSignalsClient, node.keyAttributes, timeRange, direction, hop < depth)
+				results[i] = neighbours
+				return err
+			})
+		}
+		if err := group.Wait(); err != nil {
+			return nil, nil, false, err
+		}
+
+		// The results are added in the order of the services so the graph does not depend on which request finished
+		// first.
+		var next []*dependencyGraphNode
+		visit := func(keyAttributes map[string]string) *dependencyGraphNode {
+			neighbour, added := addNode(keyAttributes)
+			if added {
+				next = append(next, neighbour)
+			}
+			return neighbour
+		}
+		for i, node := range services {
+			node.metrics = results[i].metrics
+			for _, dependency := range results[i].dependencies {
+				if target := visit(dependency.DependencyKeyAttributes); target != nil {
+					addEdge(node.id, target.id, dependency.MetricReferences)
+				}
+			}
+			for _, dependent := range results[i].dependents {
+				if source := visit(dependent.DependentKeyAttributes); source != nil {
+					addEdge(source.id, node.id, dependent.MetricReferences)
+				}
+			}
+		}
+		level = next
+	}
+	return nodes, edges, truncated, nil
+}
+
+// getServiceNeighbours returns the metrics of the operations of the service and, if withCalls is set, its dependencies
+// and dependents in the direction.
+func getServiceNeighbours(ctx context.Context, appSignalsClient AppSignalsClient, keyAttributes map[string]string, timeRange backend.TimeRange, direction string, withCalls bool) (serviceNeighbours, error) {
+	var neighbours serviceNeighbours
+	operations, err := listAllServiceOperations(ctx, appSignalsClient, keyAttributes, timeRange)
+	if err != nil {
+		return neighbours, err
+	}
+	for _, operation := range operations {
+		neighbours.metrics = append(neighbours.metrics, operation.MetricReferences...)
+	}
+	if !withCalls {
+		return neighbours, nil
+	}
+
+	if direction != FocusDirectionUpstream {
+		pager := applicationsignals.NewListServiceDependenciesPaginator(appSignalsClient, &applicationsignals.ListServiceDependenciesInput{
+			StartTime:     &timeRange.From,
+			EndTime:       &timeRange.To,
+			KeyAttributes: keyAttributes,
+		})
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return neighbours, backend.DownstreamError(err)
+			}
+			neighbours.dependencies = append(neighbours.dependencies, page.ServiceDependencies...)
+		}
+	}
+
+	if direction != FocusDirectionDownstream {
+		pager := applicationsignals.NewListServiceDependentsPaginator(appSignalsClient, &applicationsignals.ListServiceDependentsInput{
+			StartTime:     &timeRange.From,
+			EndTime:       &timeRange.To,
+			KeyAttributes: keyAttributes,
+		})
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return neighbours, backend.DownstreamError(err)
+			}
+			neighbours.dependents = append(neighbours.dependents, page.ServiceDependents...)
+		}
+	}
+	return neighbours, nil
+}
+
+// metricReferenceKey identifies a metric, references to the same metric have the same key.
+func metricReferenceKey(reference appSignalsTypes.MetricReference) string {
+	dimensions := make([]string, 0, len(reference.Dimensions))
+	for _, dimension := range reference.Dimensions {
+		dimensions = append(dimensions, Dereference(dimension.Name)+"="+Dereference(dimension.Value))
+	}
+	sort.Strings(dimensions)
+	return strings.Join([]string{
+		Dereference(reference.AccountId),
+		Dereference(reference.Namespace),
+		Dereference(reference.MetricName),
+		strings.Join(dimensions, ","),
+	}, "/")
+}
+
+// serviceDependencyGraphFrames returns the nodes and edges frames with statistics computed from the metrics. Nodes
+// without metrics of their own, which are the resources and services not instrumented with Application Signals, get
+// the statistics of the calls made to them.
+func serviceDependencyGraphFrames(ctx context.Context, cloudWatchClient CloudWatchClient, nodes []*dependencyGraphNode, edges []*dependencyGraphEdge, timeRange backend.TimeRange) ([]*data.Frame, error) {
+	var references []appSignalsTypes.MetricReference
+	for _, node := range nodes {
+		references = append(references, node.metrics...)
+	}
+	for _, edge := range edges {
+		references = append(references, edge.metrics...)
+	}
+	stats, err := getMetricReferenceStatistics(ctx, cloudWatchClient, references, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	statsOf := func(metrics []appSignalsTypes.MetricReference) summaryStatistics {
+		result := summaryStatistics{}
+		for _, metric := range metrics {
+			result = result.add(stats[metricReferenceKey(metric)])
+		}
+		result.okCount = max(result.totalCount-result.errorCount-result.faultCount, 0)
+		return result
+	}
+
+	nodeNames := make(map[string]string, len(nodes))
+	incoming := make(map[string][]appSignalsTypes.MetricReference)
+	incomingKeys := make(map[string]bool)
+	for _, edge := range edges {
+		for _, metric := range edge.metrics {
+			key := edge.target + "/" + metricReferenceKey(metric)
+			if !incomingKeys[key] {
+				incomingKeys[key] = true
+				incoming[edge.target] = append(incoming[edge.target], metric)
+			}
+		}
+	}
+
+	nodesFrame := newServiceMapNodesFrame()
+	for _, node := range nodes {
+		name := node.keyAttributes["Name"]
+		if name == "" {
+			name = node.keyAttributes["Identifier"]
+		}
+		serviceType := node.keyAttributes["ResourceType"]
+		if serviceType == "" {
+			serviceType = node.keyAttributes["Type"]
+		}
+		nodeNames[node.id] = name
+
+		metrics := node.metrics
+		if len(metrics) == 0 {
+			metrics = incoming[node.id]
+		}
+		nodesFrame.AppendRow(serviceMapNode{
+			id:          node.id,
+			name:        name,
+			serviceType: serviceType,
+			stats:       statsOf(metrics),
+			startTime:   &timeRange.From,
+			endTime:     &timeRange.To,
+		}.values()...)
+	}
+
+	edgesFrame := newServiceMapEdgesFrame()
+	for _, edge := range edges {
+		edgesFrame.AppendRow(serviceMapEdge{
+			id:         fmt.Sprintf("%s__%s", edge.source, edge.target),
+			source:     edge.source,
+			sourceName: nodeNames[edge.source],
+			target:     edge.target,
+			targetName: nodeNames[edge.target],
+			stats:      statsOf(edge.metrics),
+			startTime:  &timeRange.From,
+			endTime:    &timeRange.To,
+		}.values()...)
+	}
+
+	return []*data.Frame{nodesFrame, edgesFrame}, nil
+}
+
+// getMetricReferenceStatistics gets the totals of the latency, error and fault metrics over the time range with
+// GetMetricData and returns them by metricReferenceKey. The number of requests is the sample count of the latency.
+func getMetricReferenceStatistics(ctx context.Context, cloudWatchClient CloudWatchClient, references []appSignalsTypes.MetricReference, timeRange backend.TimeRange) (map[string]summaryStatistics, error) {
+	type metricDataQuery struct {
+		key        string
+		metricType string
+		statistic  string
+		reference  appSignalsTypes.MetricReference
+	}
+	var queries []metricDataQuery
+	seen := make(map[string]bool)
+	for _, reference := range references {
+		key := metricReferenceKey(reference)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		metricType := strings.ToUpper(Dereference(reference.MetricType))
+		switch metricType {
+		case "LATENCY":
+			queries = append(queries,
+				metricDataQuery{key: key, metricType: metricType, statistic: "Sum", reference: reference},
+				metricDataQuery{key: key, metricType: "REQUESTS", statistic: "SampleCount", reference: reference},
+			)
+		case "ERROR", "FAULT":
+			queries = append(queries, metricDataQuery{key: key, metricType: metricType, statistic: "Sum", reference: reference})
+		}
+	}
+
+	// A single period over the whole time range, the values are summed anyway if there are more.
+	period := metricDataPeriod(timeRange.From, timeRange.Duration())
+
+	stats := make(map[string]summaryStatistics)
+	for start := 0; start < len(queries); start += maxMetricDataQueries {
+		end := min(start+maxMetricDataQueries, len(queries))
+
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime: &timeRange.From,
+			EndTime:   &timeRange.To,
+		}
+		for i, query := range queries[start:end] {
+			input.MetricDataQueries = append(input.MetricDataQueries, metricReferenceDataQuery(i, query.reference, period, query.statistic))
+		}
+
+		pager := cloudwatch.NewGetMetricDataPaginator(cloudWatchClient, input)
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, backend.DownstreamError(err)
+			}
+			for _, result := range page.MetricDataResults {
+				var i int
+				if _, err := fmt.Sscanf(Dereference(result.Id), "m%d", &i); err != nil || i < 0 || i >= end-start {
+					continue
+				}
+				var sum float64
+				for _, value := range result.Values {
+					sum += value
+				}
+
+				query := queries[start+i]
+				metricStats := stats[query.key]
+				switch query.metricType {
+				case "LATENCY":
+					// Application Signals reports the latency in milliseconds
+					metricStats.totalResponseTime += sum / 1000
+				case "REQUESTS":
+					metricStats.totalCount += int64(math.Round(sum))
+				case "ERROR":
+					metricStats.errorCount += int64(math.Round(sum))
+				case "FAULT":
+					metricStats.faultCount += int64(math.Round(sum))
+				}
+				stats[query.key] = metricStats
+			}
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,61 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+// treeAppSignalsClient returns a dependency tree where every service sN calls services s3N+1, s3N+2 and s3N+3.
+type treeAppSignalsClient struct {
+	AppSignalsClient
+}
+
+func (client *treeAppSignalsClient) ListServiceOperations(context.Context, *applicationsignals.ListServiceOperationsInput, ...func(*applicationsignals.Options)) (*applicationsignals.ListServiceOperationsOutput, error) {
+	return &applicationsignals.ListServiceOperationsOutput{}, nil
+}
+
+func (client *treeAppSignalsClient) ListServiceDependencies(_ context.Context, input *applicationsignals.ListServiceDependenciesInput, _ ...func(*applicationsignals.Options)) (*applicationsignals.ListServiceDependenciesOutput, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(input.KeyAttributes["Name"], "s"))
+	if err != nil {
+		return nil, err
+	}
+	output := &applicationsignals.ListServiceDependenciesOutput{}
+	for i := 1; i <= 3; i++ {
+		output.ServiceDependencies = append(output.ServiceDependencies, appSignalsTypes.ServiceDependency{
+			DependencyKeyAttributes: map[string]string{"Type": appSignalsServiceType, "Name": fmt.Sprintf("s%d", 3*n+i)},
+		})
+	}
+	return output, nil
+}
+
+func TestWalkServiceDependencyGraph(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+	root := map[string]string{"Type": appSignalsServiceType, "Name": "s0"}
+
+	nodes, edges, truncated, err := walkServiceDependencyGraph(context.Background(), &treeAppSignalsClient{}, root, timeRange, 1, FocusDirectionDownstream, 10)
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Len(t, nodes, 4)
+	require.Len(t, edges, 3)
+
+	nodes, edges, truncated, err = walkServiceDependencyGraph(context.Background(), &treeAppSignalsClient{}, root, timeRange, 5, FocusDirectionDownstream, 10)
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Len(t, nodes, 10)
+	// Edges to the nodes which did not fit are left out
+	require.Len(t, edges, 9)
+	// Nodes are in the breadth first order
+	for i, node := range nodes {
+		require.Equal(t, fmt.Sprintf("s%d", i), node.keyAttributes["Name"])
+	}
+}
@@ -0,0 +1,113 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type GetServiceDetailsQueryData struct {
+	Region        string `json:"region,omitempty"`
+	ServiceString string `json:"serviceString,omitempty"`
+	// AttributesFormat is AttributesFormatString or AttributesFormatJSON.
+	AttributesFormat string `json:"attributesFormat,omitempty"`
+}
+
+// getSingleServiceDetails returns everything Application Signals knows about a single service: a frame with the
+// attributes of the service as key/value rows, a frame with its metric references and a frame with the log groups
+// of the service.
+func (ds *Datasource) getSingleServiceDetails(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetServiceDetailsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	if len(queryData.ServiceString) == 0 {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("Service not set on query"))
+	}
+
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	serviceMap := map[string]string{}
+	err = json.Unmarshal([]byte(queryData.ServiceString), &serviceMap)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleServiceDetails", "RefID", query.RefID, "service", queryData.ServiceString)
+
+	output, err := appSignalsClient.GetService(ctx, &applicationsignals.GetServiceInput{
+		StartTime:     &query.TimeRange.From,
+		EndTime:       &query.TimeRange.To,
+		KeyAttributes: serviceMap,
+	})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+	}
+
+	attributesFrame := data.NewFrame(
+		"ServiceAttributes",
+		data.NewField("Key", nil, []string{}),
+		data.NewField("Value", nil, []string{}),
+	)
+	metricsFrame := data.NewFrame(
+		"ServiceMetricReferences",
+		data.NewField("MetricName", nil, []*string{}),
+		data.NewField("MetricType", nil, []*string{}),
+		data.NewField("Namespace", nil, []*string{}),
+		data.NewField("AccountId", nil, []*string{}),
+		newAttributesField("Dimensions", queryData.AttributesFormat),
+	)
+	logGroupsFrame := data.NewFrame(
+		"ServiceLogGroups",
+		data.NewField("LogGroupName", nil, []string{}),
+		data.NewField("AwsAccountId", nil, []string{}),
+	)
+
+	// The log groups are returned both with the service and next to it depending on the API version.
+	logGroupReferences := output.LogGroupReferences
+	if output.Service != nil {
+		for _, attributeMap := range output.Service.AttributeMaps {
+			// sort the keys to ensure consistent ordering and testability
+			keys := make([]string, 0, len(attributeMap))
+			for key := range attributeMap {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				attributesFrame.AppendRow(key, attributeMap[key])
+			}
+		}
+
+		for _, metric := range output.Service.MetricReferences {
+			dimensions := attributesValue(dimensionsMap(metric.Dimensions), queryData.AttributesFormat, dimensionsString(metric.Dimensions))
+			metricsFrame.AppendRow(metric.MetricName, metric.MetricType, metric.Namespace, metric.AccountId, dimensions)
+		}
+
+		logGroupReferences = append(logGroupReferences, output.Service.LogGroupReferences...)
+	}
+
+	var logGroupNames []string
+	for _, reference := range logGroupReferences {
+		logGroupName := reference["Identifier"]
+		if logGroupName == "" || slices.Contains(logGroupNames, logGroupName) {
+			continue
+		}
+		logGroupNames = append(logGroupNames, logGroupName)
+		logGroupsFrame.AppendRow(logGroupName, reference["AwsAccountId"])
+	}
+
+	return backend.DataResponse{
+		Frames: data.Frames{attributesFrame, metricsFrame, logGroupsFrame},
+	}
+}
@@ -0,0 +1,276 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// Maximum number of SLO ids BatchGetServiceLevelObjectiveBudgetReport accepts in one request.
+	maxSLOBudgetReportIds = 50
+
+	maxConcurrentSLORequests = 5
+)
+
+type GetServiceLevelObjectiveDetailsQueryData struct {
+	Region string `json:"region"`
+
+	// Used to select the SLOs, all the SLOs are used if not set.
+	ServiceString string `json:"serviceString,omitempty"`
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// getSingleServiceLevelObjectiveDetails returns a table with the goal, SLI and budget of each SLO at the end of the time
+// range.
+func (ds *Datasource) getSingleServiceLevelObjectiveDetails(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetServiceLevelObjectiveDetailsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleServiceLevelObjectiveDetails", "RefID", query.RefID, "service", queryData.ServiceString, "operation", queryData.OperationName)
+
+	sloIds, err := listSLOIds(ctx, appSignalsClient, queryData.ServiceString, queryData.OperationName)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	slos, err := getSLOs(ctx, appSignalsClient, sloIds)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	reports, err := getSLOBudgetReports(ctx, appSignalsClient, sloIds, query.TimeRange.To)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frame := data.NewFrame(
+		"ServiceLevelObjectiveDetails",
+		data.NewField("Name", nil, []string{}),
+		data.NewField("Description", nil, []string{}),
+		data.NewField("Evaluation type", nil, []string{}),
+		data.NewField("SLI metric", nil, []string{}),
+		data.NewField("SLI operation", nil, []string{}),
+		data.NewField("SLI threshold", nil, []*float64{}),
+		data.NewField("Comparison operator", nil, []string{}),
+		data.NewField("Interval", nil, []string{}),
+		data.NewField("Attainment goal", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Warning threshold", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Attainment", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Budget remaining", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Budget consumed", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Budget status", nil, []string{}),
+		data.NewField("ARN", nil, []string{}),
+	)
+	for i, slo := range slos {
+		if slo == nil {
+			continue
+		}
+		var report appSignalsTypes.ServiceLevelObjectiveBudgetReport
+		if r, ok := reports[sloIds[i]]; ok {
+			report = r
+		}
+		sli := sloIndicator(slo)
+		goal := Dereference(slo.Goal)
+		remaining := sloBudgetRemaining(report)
+		var consumed *float64
+		if remaining != nil {
+			consumed = aws.Float64(100 - *remaining)
+		}
+		frame.AppendRow(
+			Dereference(slo.Name),
+			Dereference(slo.Description),
+			string(slo.EvaluationType),
+			sli.metricType,
+			sli.operationName,
+			sli.threshold,
+			sli.comparisonOperator,
+			sloIntervalString(goal.Interval),
+			goal.AttainmentGoal,
+			goal.WarningThreshold,
+			report.Attainment,
+			remaining,
+			consumed,
+			string(report.BudgetStatus),
+			Dereference(slo.Arn),
+		)
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// getSLOs returns the SLOs in the same order as the ids.
+func getSLOs(ctx context.Context, appSignalsClient AppSignalsClient, sloIds []string) ([]*appSignalsTypes.ServiceLevelObjective, error) {
+	slos := make([]*appSignalsTypes.ServiceLevelObjective, len(sloIds))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentSLORequests)
+	for i, sloId := range sloIds {
+		group.Go(func() error {
+			output, err := appSignalsClient.GetServiceLevelObjective(groupCtx, &applicationsignals.GetServiceLevelObjectiveInput{Id: aws.String(sloId)})
+			if err != nil {
+				return backend.DownstreamError(err)
+			}
+			slos[i] = output.Slo
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return slos, nil
+}
+
+// listSLOIds returns the ids of the SLOs of the service and operation, or of all the SLOs if they are empty.
+func listSLOIds(ctx context.Context, appSignalsClient AppSignalsClient, serviceString string, operationName string) ([]string, error) {
+	input := &applicationsignals.ListServiceLevelObjectivesInput{}
+	if serviceString != "" {
+		keyAttributes := map[string]string{}
+		err := json.Unmarshal([]byte(serviceString), &keyAttributes)
+		if err != nil {
+			return nil, backend.PluginError(err)
+		}
+		input.KeyAttributes = keyAttributes
+	}
+	if operationName != "" {
+		input.OperationName = aws.String(operationName)
+	}
+
+	var sloIds []string
+	pager := applicationsignals.NewListServiceLevelObjectivesPaginator(appSignalsClient, input)
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		for _, slo := range page.SloSummaries {
+			// Name works as an id too but only for SLOs in the same account
+			if slo.Arn != nil {
+				sloIds = append(sloIds, *slo.Arn)
+			} else if slo.Name != nil {
+				sloIds = append(sloIds, *slo.Name)
+			}
+		}
+	}
+	return sloIds, nil
+}
+
+// getSLOBudgetReports returns the budget reports of the SLOs at the timestamp by the SLO id.
+func getSLOBudgetReports(ctx context.Context, appSignalsClient AppSignalsClient, sloIds []string, timestamp time.Time) (map[string]appSignalsTypes.ServiceLevelObjectiveBudgetReport, error) {
+	reports := make(map[string]appSignalsTypes.ServiceLevelObjectiveBudgetReport, len(sloIds))
+	for start := 0; start < len(sloIds); start += maxSLOBudgetReportIds {
+		end := min(start+maxSLOBudgetReportIds, len(sloIds))
+		output, err := appSignalsClient.BatchGetServiceLevelObjectiveBudgetReport(ctx, &applicationsignals.BatchGetServiceLevelObjectiveBudgetReportInput{
+			SloIds:    sloIds[start:end],
+			Timestamp: aws.Time(timestamp),
+		})
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		for _, report := range output.Reports {
+			for _, sloId := range sloIds[start:end] {
+				if sloId == Dereference(report.Arn) || sloId == Dereference(report.Name) {
+					reports[sloId] = report
+					break
+				}
+			}
+		}
+	}
+	return reports, nil
+}
+
+// sloBudgetRemaining returns the remaining error budget in percent of the total budget. Period based SLOs have the
+// budget in seconds and request based ones in requests.
+func sloBudgetRemaining(report appSignalsTypes.ServiceLevelObjectiveBudgetReport) *float64 {
+	total, remaining := report.TotalBudgetSeconds, report.BudgetSecondsRemaining
+	if report.EvaluationType == appSignalsTypes.EvaluationTypeRequestBased {
+		total, remaining = report.TotalBudgetRequests, report.BudgetRequestsRemaining
+	}
+	if total == nil || remaining == nil || *total == 0 {
+		return nil
+	}
+	return aws.Float64(float64(*remaining) / float64(*total) * 100)
+}
+
+type sloIndicatorDetails struct {
+	metricType         string
+	operationName      string
+	threshold          *float64
+	comparisonOperator string
+}
+
+// sloIndicator returns the SLI of either the period based or the request based SLO.
+func sloIndicator(slo *appSignalsTypes.ServiceLevelObjective) sloIndicatorDetails {
+	if slo.Sli != nil {
+		metric := Dereference(slo.Sli.SliMetric)
+		return sloIndicatorDetails{
+			metricType:         string(metric.MetricType),
+			operationName:      Dereference(metric.OperationName),
+			threshold:          slo.Sli.MetricThreshold,
+			comparisonOperator: string(slo.Sli.ComparisonOperator),
+		}
+	}
+	if slo.RequestBasedSli != nil {
+		metric := Dereference(slo.RequestBasedSli.RequestBasedSliMetric)
+		return sloIndicatorDetails{
+			metricType:         string(metric.MetricType),
+			operationName:      Dereference(metric.OperationName),
+			threshold:          slo.RequestBasedSli.MetricThreshold,
+			comparisonOperator: string(slo.RequestBasedSli.ComparisonOperator),
+		}
+	}
+	return sloIndicatorDetails{}
+}
+
+// sloIntervalString returns the interval in a readable form, for example "7 day rolling".
+func sloIntervalString(interval appSignalsTypes.Interval) string {
+	switch interval := interval.(type) {
+	case *appSignalsTypes.IntervalMemberRollingInterval:
+		return fmt.Sprintf("%d %s rolling", Dereference(interval.Value.Duration), strings.ToLower(string(interval.Value.DurationUnit)))
+	case *appSignalsTypes.IntervalMemberCalendarInterval:
+		return fmt.Sprintf("%d %s calendar", Dereference(interval.Value.Duration), strings.ToLower(string(interval.Value.DurationUnit)))
+	}
+	return ""
+}
+
+// sloIntervalDuration returns the length of the interval, calendar months are counted as 30 days.
+func sloIntervalDuration(interval appSignalsTypes.Interval) time.Duration {
+	var duration int32
+	var unit appSignalsTypes.DurationUnit
+	switch interval := interval.(type) {
+	case *appSignalsTypes.IntervalMemberRollingInterval:
+		duration, unit = Dereference(interval.Value.Duration), interval.Value.DurationUnit
+	case *appSignalsTypes.IntervalMemberCalendarInterval:
+		duration, unit = Dereference(interval.Value.Duration), interval.Value.DurationUnit
+	}
+	switch unit {
+	case appSignalsTypes.DurationUnitMinute:
+		return time.Duration(duration) * time.Minute
+	case appSignalsTypes.DurationUnitHour:
+		return time.Duration(duration) * time.Hour
+	case appSignalsTypes.DurationUnitDay:
+		return time.Duration(duration) * 24 * time.Hour
+	case appSignalsTypes.DurationUnitMonth:
+		return time.Duration(duration) * 30 * 24 * time.Hour
+	}
+	return 0
+}
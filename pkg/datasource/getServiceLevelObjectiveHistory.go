@@ -0,0 +1,283 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Each point of the SLO history needs a budget report request, so there is a limit on them.
+const maxSLOHistoryPoints = 30
+
+type GetServiceLevelObjectiveHistoryQueryData struct {
+	Region string `json:"region"`
+
+	// Used to select the SLOs, all the SLOs are used if not set.
+	ServiceString string `json:"serviceString,omitempty"`
+	OperationName string `json:"operationName,omitempty"`
+
+	// BurnRateThreshold marks the times the burn rate is above it as breaches too. Only the times the budget status is
+	// breached are marked if it is not set.
+	BurnRateThreshold float64 `json:"burnRateThreshold,omitempty"`
+}
+
+// sloHistory is the budget of a single SLO over the time range.
+type sloHistory struct {
+	name   string
+	points []sloHistoryPoint
+}
+
+type sloHistoryPoint struct {
+	timestamp  time.Time
+	attainment *float64
+	remaining  *float64
+	// burnRate is how fast the budget was used since the previous point relative to the rate that uses the whole budget
+	// in the interval of the SLO, so 1 means the budget runs out exactly at the end of the interval.
+	burnRate *float64
+	status   appSignalsTypes.ServiceLevelObjectiveBudgetStatus
+}
+
+// breach returns why the point is a breach, or an empty string if it is not one.
+func (point sloHistoryPoint) breach(burnRateThreshold float64) string {
+	if point.status == appSignalsTypes.ServiceLevelObjectiveBudgetStatusBreached {
+		return "Breached"
+	}
+	if burnRateThreshold > 0 && point.burnRate != nil && *point.burnRate > burnRateThreshold {
+		return "Burn rate"
+	}
+	return ""
+}
+
+// getSingleServiceLevelObjectiveHistory returns the attainment, remaining budget and burn rate of each SLO over the time
+// range. The frame is a long time series with the SLO name as a label and has a Breached field which is 1 when the SLO
+// was breached or the burn rate was above the threshold, so it can be used in alert rules.
+func (ds *Datasource) getSingleServiceLevelObjectiveHistory(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData, histories, err := ds.getSLOHistories(ctx, query, pluginContext)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frame := data.NewFrame(
+		"ServiceLevelObjectiveHistory",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("Attainment", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Budget remaining", nil, []*float64{}).SetConfig(&data.FieldConfig{Unit: "percent"}),
+		data.NewField("Burn rate", nil, []*float64{}),
+		data.NewField("Breached", nil, []float64{}),
+		data.NewField("slo", nil, []string{}),
+	)
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesLong}
+
+	// Long frames need to be sorted by time, the points of every SLO have the same times.
+	if len(histories) > 0 {
+		for i := range histories[0].points {
+			for _, history := range histories {
+				point := history.points[i]
+				breached := 0.0
+				if point.breach(queryData.BurnRateThreshold) != "" {
+					breached = 1
+				}
+				frame.AppendRow(point.timestamp, point.attainment, point.remaining, point.burnRate, breached, history.name)
+			}
+		}
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// getSingleServiceLevelObjectiveBreaches returns the times the SLOs were breached or the burn rate was above the
+// threshold in the annotation format. Consecutive breaches of an SLO for the same reason are a single annotation.
+func (ds *Datasource) getSingleServiceLevelObjectiveBreaches(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData, histories, err := ds.getSLOHistories(ctx, query, pluginContext)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+	step := sloBudgetReportsStep(query)
+
+	frame := data.NewFrame(
+		"ServiceLevelObjectiveBreaches",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("timeEnd", nil, []time.Time{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("text", nil, []string{}),
+		// Grafana splits the tags by comma
+		data.NewField("tags", nil, []string{}),
+	)
+
+	for _, history := range histories {
+		for start := 0; start < len(history.points); start++ {
+			reason := history.points[start].breach(queryData.BurnRateThreshold)
+			if reason == "" {
+				continue
+			}
+			end := start
+			for end+1 < len(history.points) && history.points[end+1].breach(queryData.BurnRateThreshold) == reason {
+				end++
+			}
+			// The breach lasts until the next point which is not a breach.
+			timeEnd := history.points[end].timestamp.Add(step)
+			if timeEnd.After(query.TimeRange.To) {
+				timeEnd = query.TimeRange.To
+			}
+
+			title := fmt.Sprintf("SLO %s breached", history.name)
+			if reason == "Burn rate" {
+				title = fmt.Sprintf("SLO %s burn rate above %g", history.name, queryData.BurnRateThreshold)
+			}
+			frame.AppendRow(
+				history.points[start].timestamp,
+				timeEnd,
+				title,
+				sloBreachDescription(history.points[start:end+1]),
+				strings.Join([]string{"SLO", reason, history.name}, ","),
+			)
+			start = end
+		}
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// getSLOHistories returns the history of each SLO matching the query.
+func (ds *Datasource) getSLOHistories(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) (*GetServiceLevelObjectiveHistoryQueryData, []sloHistory, error) {
+	queryData := &GetServiceLevelObjectiveHistoryQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return nil, nil, backend.PluginError(err)
+	}
+
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return nil, nil, backend.PluginError(err)
+	}
+
+	log.DefaultLogger.Debug("getSLOHistories", "RefID", query.RefID, "service", queryData.ServiceString, "operation", queryData.OperationName)
+
+	sloIds, err := listSLOIds(ctx, appSignalsClient, queryData.ServiceString, queryData.OperationName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sloIds) == 0 {
+		return queryData, nil, nil
+	}
+
+	// The SLOs are only needed for the length of their interval to calculate the burn rate.
+	slos, err := getSLOs(ctx, appSignalsClient, sloIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	points, err := getSLOBudgetReportsOverTime(ctx, appSignalsClient, sloIds, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	step := sloBudgetReportsStep(query)
+
+	histories := make([]sloHistory, len(sloIds))
+	for i, sloId := range sloIds {
+		histories[i].name = sloId
+		var intervalDuration time.Duration
+		if slos[i] != nil {
+			histories[i].name = Dereference(slos[i].Name)
+			intervalDuration = sloIntervalDuration(Dereference(slos[i].Goal).Interval)
+		}
+
+		var previousRemaining *float64
+		for j, point := range points {
+			report := point.reports[sloId]
+			historyPoint := sloHistoryPoint{
+				timestamp:  point.timestamp,
+				attainment: report.Attainment,
+				remaining:  sloBudgetRemaining(report),
+				status:     report.BudgetStatus,
+			}
+			// The first point is before the time range and is only used for the burn rate of the next one.
+			if j == 0 {
+				previousRemaining = historyPoint.remaining
+				continue
+			}
+			if previousRemaining != nil && historyPoint.remaining != nil && intervalDuration > 0 {
+				// The budget grows back when old data leaves a rolling interval or a calendar interval starts again,
+				// that is not a negative burn rate.
+				consumed := max(*previousRemaining-*historyPoint.remaining, 0)
+				expected := 100 * step.Seconds() / intervalDuration.Seconds()
+				burnRate := consumed / expected
+				historyPoint.burnRate = &burnRate
+			}
+			previousRemaining = historyPoint.remaining
+			histories[i].points = append(histories[i].points, historyPoint)
+		}
+	}
+	return queryData, histories, nil
+}
+
+// sloBudgetReportsPoint has the budget reports of the SLOs at a single time by the SLO id.
+type sloBudgetReportsPoint struct {
+	timestamp time.Time
+	reports   map[string]appSignalsTypes.ServiceLevelObjectiveBudgetReport
+}
+
+// getSLOBudgetReportsOverTime returns the budget reports of the SLOs at evenly spaced times of the time range. The first
+// point is a step before the time range so every point in it has a previous one to compute the burn rate from.
+func getSLOBudgetReportsOverTime(ctx context.Context, appSignalsClient AppSignalsClient, sloIds []string, query backend.DataQuery) ([]sloBudgetReportsPoint, error) {
+	step := sloBudgetReportsStep(query)
+
+	start := query.TimeRange.From.Truncate(step)
+	if !start.Before(query.TimeRange.From) {
+		start = start.Add(-step)
+	}
+
+	var points []sloBudgetReportsPoint
+	for timestamp := start; !timestamp.After(query.TimeRange.To); timestamp = timestamp.Add(step) {
+		reports, err := getSLOBudgetReports(ctx, appSignalsClient, sloIds, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, sloBudgetReportsPoint{timestamp: timestamp, reports: reports})
+	}
+	return points, nil
+}
+
+// sloBudgetReportsStep returns the time between the budget reports of getSLOBudgetReportsOverTime.
+func sloBudgetReportsStep(query backend.DataQuery) time.Duration {
+	return max(query.Interval, query.TimeRange.To.Sub(query.TimeRange.From)/maxSLOHistoryPoints, time.Minute)
+}
+
+// sloBreachDescription describes the lowest attainment and budget and the highest burn rate during the breach.
+func sloBreachDescription(points []sloHistoryPoint) string {
+	var attainment, remaining, burnRate *float64
+	for _, point := range points {
+		if point.attainment != nil && (attainment == nil || *point.attainment < *attainment) {
+			attainment = point.attainment
+		}
+		if point.remaining != nil && (remaining == nil || *point.remaining < *remaining) {
+			remaining = point.remaining
+		}
+		if point.burnRate != nil && (burnRate == nil || *point.burnRate > *burnRate) {
+			burnRate = point.burnRate
+		}
+	}
+
+	var parts []string
+	if attainment != nil {
+		parts = append(parts, fmt.Sprintf("Attainment: %.2f%%", *attainment))
+	}
+	if remaining != nil {
+		parts = append(parts, fmt.Sprintf("Budget remaining: %.2f%%", *remaining))
+	}
+	if burnRate != nil {
+		parts = append(parts, fmt.Sprintf("Burn rate: %.2f", *burnRate))
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,52 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+// budgetReportsAppSignalsClient records the timestamps of the budget reports it was asked for.
+type budgetReportsAppSignalsClient struct {
+	AppSignalsClient
+	timestamps []time.Time
+}
+
+func (client *budgetReportsAppSignalsClient) BatchGetServiceLevelObjectiveBudgetReport(_ context.Context, input *applicationsignals.BatchGetServiceLevelObjectiveBudgetReportInput, _ ...func(*applicationsignals.Options)) (*applicationsignals.BatchGetServiceLevelObjectiveBudgetReportOutput, error) {
+	client.timestamps = append(client.timestamps, *input.Timestamp)
+	return &applicationsignals.BatchGetServiceLevelObjectiveBudgetReportOutput{}, nil
+}
+
+func TestGetSLOBudgetReportsOverTime(t *testing.T) {
+	to := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	t.Run("starts a step before an aligned time range", func(t *testing.T) {
+		client := &budgetReportsAppSignalsClient{}
+		query := backend.DataQuery{
+			Interval:  10 * time.Minute,
+			TimeRange: backend.TimeRange{From: to.Add(-30 * time.Minute), To: to},
+		}
+		points, err := getSLOBudgetReportsOverTime(context.Background(), client, []string{"slo"}, query)
+		require.NoError(t, err)
+		require.Len(t, points, 5)
+		require.Equal(t, to.Add(-40*time.Minute), points[0].timestamp)
+		require.Equal(t, to, points[4].timestamp)
+		require.Equal(t, to.Add(-40*time.Minute), client.timestamps[0])
+	})
+
+	t.Run("starts at the step before an unaligned time range", func(t *testing.T) {
+		client := &budgetReportsAppSignalsClient{}
+		query := backend.DataQuery{
+			Interval:  10 * time.Minute,
+			TimeRange: backend.TimeRange{From: to.Add(-25 * time.Minute), To: to},
+		}
+		points, err := getSLOBudgetReportsOverTime(context.Background(), client, []string{"slo"}, query)
+		require.NoError(t, err)
+		require.Len(t, points, 4)
+		require.Equal(t, to.Add(-30*time.Minute), points[0].timestamp)
+	})
+}
@@ -3,7 +3,11 @@ package datasource
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/xray"
 	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
 
@@ -16,9 +20,27 @@ type GetServiceMapQueryData struct {
 	Region     string           `json:"region"`
 	Group      *xraytypes.Group `json:"group"`
 	AccountIds []string         `json:"accountIds,omitempty"`
+	// LegacyFormat returns each service as a JSON string in a single frame, which is how the frontend used to build
+	// the node graph before the backend did it.
+	LegacyFormat bool `json:"legacyFormat,omitempty"`
+
+	// FocusService limits the map to the services with this name and their neighbours up to FocusDepth hops away in
+	// the FocusDirection. Zero FocusDepth means no limit.
+	FocusService   string `json:"focusService,omitempty"`
+	FocusDepth     int    `json:"focusDepth,omitempty"`
+	FocusDirection string `json:"focusDirection,omitempty"`
+	// ExcludeTypes removes services of these types, for example AWS::DynamoDB::Table, from the map.
+	ExcludeTypes []string `json:"excludeTypes,omitempty"`
+	// CollapseClients merges all the client nodes into a single one.
+	CollapseClients bool `json:"collapseClients,omitempty"`
+
+	// CompareTimeShift, for example 1h or 1d, turns on comparison with the same time range shifted back by this
+	// duration. The returned nodes and edges are annotated with what changed between the two time ranges. It is not
+	// supported with LegacyFormat.
+	CompareTimeShift string `json:"compareTimeShift,omitempty"`
 }
 
-// getSingleTrace returns single trace from BatchGetTraces API and unmarshals it.
+// getSingleServiceMap returns the service graph from GetServiceGraph API as node graph frames.
 func (ds *Datasource) getSingleServiceMap(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
 	queryData := &GetServiceMapQueryData{}
 	err := json.Unmarshal(query.JSON, queryData)
@@ -27,21 +49,52 @@ func (ds *Datasource) getSingleServiceMap(ctx context.Context, query backend.Dat
 		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
 	}
 
+	if err := validateFocusDirection(queryData.FocusDirection); err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+	}
+
+	if queryData.LegacyFormat && queryData.CompareTimeShift != "" {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("compare time shift is not supported with the legacy format"))
+	}
+
 	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
 	if err != nil {
 		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
 	}
 
-	var frame = data.NewFrame(
-		"ServiceMap",
-		data.NewField("Service", nil, []string{}),
-	)
-
 	log.DefaultLogger.Debug("getSingleServiceMap", "RefID", query.RefID)
+
+	if queryData.CompareTimeShift != "" {
+		return getServiceMapComparison(ctx, xrayClient, query, queryData)
+	}
+
+	services, err := getServiceGraph(ctx, xrayClient, query.TimeRange.From, query.TimeRange.To, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	services = filterServiceGraph(services, queryData)
+
+	if queryData.LegacyFormat {
+		return backend.DataResponse{
+			Frames: []*data.Frame{serviceMapJSONFrame(services)},
+		}
+	}
+
+	return backend.DataResponse{
+		Frames: serviceMapNodeGraphFrames(services),
+	}
+}
+
+// getServiceGraph returns all the services from GetServiceGraph API in the time range, filtered by the account ids
+// from the query.
+func getServiceGraph(ctx context.Context, xrayClient XrayClient, from time.Time, to time.Time, queryData *GetServiceMapQueryData) ([]xraytypes.Service, error) {
 	input := &xray.GetServiceGraphInput{
-		StartTime: &query.TimeRange.From,
-		EndTime:   &query.TimeRange.To,
-		GroupName: queryData.Group.GroupName,
+		StartTime: aws.Time(from),
+		EndTime:   aws.Time(to),
+	}
+	if queryData.Group != nil {
+		input.GroupName = queryData.Group.GroupName
 	}
 
 	accountIdsToFilterBy := make(map[string]bool)
@@ -49,6 +102,7 @@ func (ds *Datasource) getSingleServiceMap(ctx context.Context, query backend.Dat
 		accountIdsToFilterBy[value] = true
 	}
 
+	var services []xraytypes.Service
 	pager := xray.NewGetServiceGraphPaginator(xrayClient, input)
 	var pagerError error
 	for pager.HasMorePages() {
@@ -69,21 +123,192 @@ func (ds *Datasource) getSingleServiceMap(ctx context.Context, query backend.Dat
 					continue
 				}
 			}
-			bytes, err := json.Marshal(service)
-			if err != nil {
-				// TODO: probably does not make sense to fail just because of one service but I assume the layout will fail
-				//  because of some edge not connected to anything.
-				log.DefaultLogger.Error("getSingleServiceMap failed to marshal service", "Name", service.Name, "ReferenceId", service.ReferenceId)
-			}
-			frame.AppendRow(string(bytes))
+			services = append(services, service)
 		}
 	}
 
 	if pagerError != nil {
-		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(pagerError))
+		return nil, backend.DownstreamError(pagerError)
+	}
+	return services, nil
+}
+
+// serviceMapJSONFrame returns the legacy service map format where each service is sent as a json string and the
+// frontend transforms it into a node graph.
+func serviceMapJSONFrame(services []xraytypes.Service) *data.Frame {
+	frame := data.NewFrame(
+		"ServiceMap",
+		data.NewField("Service", nil, []string{}),
+	)
+	for _, service := range services {
+		bytes, err := json.Marshal(service)
+		if err != nil {
+			// TODO: probably does not make sense to fail just because of one service but I assume the layout will fail
+			//  because of some edge not connected to anything.
+			log.DefaultLogger.Error("getSingleServiceMap failed to marshal service", "Name", service.Name, "ReferenceId", service.ReferenceId)
+		}
+		frame.AppendRow(string(bytes))
 	}
+	return frame
+}
 
-	return backend.DataResponse{
-		Frames: []*data.Frame{frame},
+// serviceMapNode is a service from the service graph with statistics needed for the node graph.
+type serviceMapNode struct {
+	id          string
+	name        string
+	serviceType string
+	stats       summaryStatistics
+	startTime   *time.Time
+	endTime     *time.Time
+}
+
+type serviceMapEdge struct {
+	id         string
+	source     string
+	sourceName string
+	target     string
+	targetName string
+	stats      summaryStatistics
+	startTime  *time.Time
+	endTime    *time.Time
+}
+
+// serviceGraphNodes converts the services to nodes and edges. nodeId is used to get id of each service, which allows to
+// use something else than ReferenceId which is unique only in a single API response.
+func serviceGraphNodes(services []xraytypes.Service, nodeId func(xraytypes.Service) string) ([]serviceMapNode, []serviceMapEdge) {
+	servicesByReferenceId := make(map[int32]xraytypes.Service)
+	for _, service := range services {
+		servicesByReferenceId[Dereference(service.ReferenceId)] = service
+	}
+
+	var nodes []serviceMapNode
+	var edges []serviceMapEdge
+	for _, service := range services {
+		node := serviceMapNode{
+			id:          nodeId(service),
+			name:        Dereference(service.Name),
+			serviceType: Dereference(service.Type),
+			stats:       fromServiceStatistics(service.SummaryStatistics),
+			startTime:   service.StartTime,
+			endTime:     service.EndTime,
+		}
+		// Client nodes do not have statistics of their own so we compute them from their edges.
+		if service.SummaryStatistics == nil && len(service.Edges) > 0 {
+			node.startTime, node.endTime = nil, nil
+			for _, edge := range service.Edges {
+				node.stats = node.stats.add(fromEdgeStatistics(edge.SummaryStatistics))
+				node.startTime = minTime(node.startTime, edge.StartTime)
+				node.endTime = maxTime(node.endTime, edge.EndTime)
+			}
+		}
+		nodes = append(nodes, node)
+
+		for _, edge := range service.Edges {
+			target, ok := servicesByReferenceId[Dereference(edge.ReferenceId)]
+			// When filtering by account id not every target is returned, there is no need to add an edge then.
+			if !ok {
+				continue
+			}
+			edges = append(edges, serviceMapEdge{
+				id:         fmt.Sprintf("%s__%s", node.id, nodeId(target)),
+				source:     node.id,
+				sourceName: node.name,
+				target:     nodeId(target),
+				targetName: Dereference(target.Name),
+				stats:      fromEdgeStatistics(edge.SummaryStatistics),
+				startTime:  edge.StartTime,
+				endTime:    edge.EndTime,
+			})
+		}
+	}
+	return nodes, edges
+}
+
+// serviceMapNodeGraphFrames returns nodes and edges frames in the format expected by the Grafana node graph
+// visualisation.
+func serviceMapNodeGraphFrames(services []xraytypes.Service) []*data.Frame {
+	nodes, edges := serviceGraphNodes(services, serviceNodeId)
+
+	nodesFrame := newServiceMapNodesFrame()
+	for _, node := range nodes {
+		nodesFrame.AppendRow(node.values()...)
+	}
+
+	edgesFrame := newServiceMapEdgesFrame()
+	for _, edge := range edges {
+		edgesFrame.AppendRow(edge.values()...)
+	}
+
+	return []*data.Frame{nodesFrame, edgesFrame}
+}
+
+func newServiceMapNodesFrame() *data.Frame {
+	frame := data.NewFrame(
+		"nodes",
+		data.NewField("id", nil, []string{}),
+		data.NewField("title", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Name"}),
+		data.NewField("subtitle", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Type"}),
+		data.NewField("mainstat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/t"}),
+		data.NewField("secondarystat", nil, []*float64{}).SetConfig(&data.FieldConfig{DisplayName: "Transactions per minute", Unit: "t/min"}),
+		data.NewField("arc__success", nil, []float64{}).SetConfig(arcConfig("Success", "green")),
+		data.NewField("arc__faults", nil, []float64{}).SetConfig(arcConfig("Fault", "red")),
+		data.NewField("arc__errors", nil, []float64{}).SetConfig(arcConfig("Error", "semi-dark-yellow")),
+		data.NewField("arc__throttled", nil, []float64{}).SetConfig(arcConfig("Throttled", "purple")),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+	return frame
+}
+
+// values returns the row for the frame created by newServiceMapNodesFrame.
+func (node serviceMapNode) values() []interface{} {
+	return []interface{}{
+		node.id,
+		node.name,
+		node.serviceType,
+		node.stats.averageResponseTime(),
+		node.stats.requestsPerMinute(node.startTime, node.endTime),
+		node.stats.successRate(),
+		node.stats.faultRate(),
+		node.stats.errorRate(),
+		node.stats.throttleRate(),
+	}
+}
+
+func newServiceMapEdgesFrame() *data.Frame {
+	frame := data.NewFrame(
+		"edges",
+		data.NewField("id", nil, []string{}),
+		data.NewField("source", nil, []string{}),
+		data.NewField("sourceName", nil, []string{}),
+		data.NewField("target", nil, []string{}),
+		data.NewField("targetName", nil, []string{}),
+		data.NewField("mainstat", nil, []float64{}).SetConfig(&data.FieldConfig{DisplayName: "Average response time", Unit: "ms/t"}),
+		data.NewField("secondarystat", nil, []*float64{}).SetConfig(&data.FieldConfig{DisplayName: "Transactions per minute", Unit: "t/min"}),
+	)
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeNodeGraph}
+	return frame
+}
+
+// values returns the row for the frame created by newServiceMapEdgesFrame.
+func (edge serviceMapEdge) values() []interface{} {
+	return []interface{}{
+		edge.id,
+		edge.source,
+		edge.sourceName,
+		edge.target,
+		edge.targetName,
+		edge.stats.averageResponseTime(),
+		edge.stats.requestsPerMinute(edge.startTime, edge.endTime),
+	}
+}
+
+func serviceNodeId(service xraytypes.Service) string {
+	return strconv.FormatInt(int64(Dereference(service.ReferenceId)), 10)
+}
+
+func arcConfig(displayName string, color string) *data.FieldConfig {
+	return &data.FieldConfig{
+		DisplayName: displayName,
+		Color:       map[string]interface{}{"mode": "fixed", "fixedColor": color},
 	}
 }
@@ -0,0 +1,292 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudWatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Maximum number of metrics GetMetricData accepts in one request.
+const maxMetricDataQueries = 500
+
+type GetServiceMetricsQueryData struct {
+	Region        string `json:"region,omitempty"`
+	ServiceString string `json:"serviceString,omitempty"`
+	// Only get the metrics of this operation, the metrics of all operations are returned if not set.
+	OperationName string `json:"operationName,omitempty"`
+
+	// Dependencies gets the metrics of the calls to the dependencies of the service instead of the metrics of its
+	// operations. DependencyString selects a single dependency with its key attributes in the same format as
+	// ServiceString.
+	Dependencies            bool   `json:"dependencies,omitempty"`
+	DependencyString        string `json:"dependencyString,omitempty"`
+	DependencyOperationName string `json:"dependencyOperationName,omitempty"`
+
+	// MetricType is Latency, Error or Fault.
+	MetricType string `json:"metricType,omitempty"`
+	// Statistic overrides the default statistic, which is Average for latency and Sum for errors and faults.
+	Statistic string `json:"statistic,omitempty"`
+}
+
+// serviceMetric is a CloudWatch metric referenced by Application Signals together with the labels of its series.
+type serviceMetric struct {
+	labels    data.Labels
+	reference appSignalsTypes.MetricReference
+}
+
+// getSingleServiceMetrics returns the CloudWatch metrics Application Signals references for the operations or the
+// dependencies of a service. There is a series for each operation, or for each operation and dependency, so the
+// metrics can be graphed without copying the references to a CloudWatch data source.
+func (ds *Datasource) getSingleServiceMetrics(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetServiceMetricsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	if len(queryData.ServiceString) == 0 {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("Service not set on query"))
+	}
+	if len(queryData.MetricType) == 0 {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("Metric type not set on query"))
+	}
+
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+	cloudWatchClient, err := ds.getCloudWatchClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleServiceMetrics", "RefID", query.RefID, "service", queryData.ServiceString, "metricType", queryData.MetricType)
+
+	serviceMap := map[string]string{}
+	err = json.Unmarshal([]byte(queryData.ServiceString), &serviceMap)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	var metrics []serviceMetric
+	if queryData.Dependencies {
+		metrics, err = listServiceDependencyMetrics(ctx, appSignalsClient, serviceMap, query.TimeRange, queryData)
+	} else {
+		metrics, err = listServiceOperationMetrics(ctx, appSignalsClient, serviceMap, query.TimeRange, queryData)
+	}
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	frames, err := getServiceMetricsData(ctx, cloudWatchClient, metrics, query, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+	return backend.DataResponse{
+		Frames: frames,
+	}
+}
+
+func listServiceOperationMetrics(ctx context.Context, appSignalsClient AppSignalsClient, serviceMap map[string]string, timeRange backend.TimeRange, queryData *GetServiceMetricsQueryData) ([]serviceMetric, error) {
+	operations, err := listAllServiceOperations(ctx, appSignalsClient, serviceMap, timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []serviceMetric
+	for _, operation := range operations {
+		if queryData.OperationName != "" && Dereference(operation.Name) != queryData.OperationName {
+			continue
+		}
+		for _, reference := range operation.MetricReferences {
+			if !strings.EqualFold(Dereference(reference.MetricType), queryData.MetricType) {
+				continue
+			}
+			metrics = append(metrics, serviceMetric{
+				labels:    data.Labels{"operation": Dereference(operation.Name)},
+				reference: reference,
+			})
+		}
+	}
+	return metrics, nil
+}
+
+// listAllServiceOperations returns all the operations of a service in the time range.
+func listAllServiceOperations(ctx context.Context, appSignalsClient AppSignalsClient, keyAttributes map[string]string, timeRange backend.TimeRange) ([]appSignalsTypes.ServiceOperation, error) {
+	var operations []appSignalsTypes.ServiceOperation
+	pager := applicationsignals.NewListServiceOperationsPaginator(appSignalsClient, &applicationsignals.ListServiceOperationsInput{
+		StartTime:     &timeRange.From,
+		EndTime:       &timeRange.To,
+		KeyAttributes: keyAttributes,
+	})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		operations = append(operations, page.ServiceOperations...)
+	}
+	return operations, nil
+}
+
+func listServiceDependencyMetrics(ctx context.Context, appSignalsClient AppSignalsClient, serviceMap map[string]string, timeRange backend.TimeRange, queryData *GetServiceMetricsQueryData) ([]serviceMetric, error) {
+	var dependencyMap map[string]string
+	if queryData.DependencyString != "" {
+		err := json.Unmarshal([]byte(queryData.DependencyString), &dependencyMap)
+		if err != nil {
+			return nil, backend.PluginError(err)
+		}
+	}
+
+	var metrics []serviceMetric
+	pager := applicationsignals.NewListServiceDependenciesPaginator(appSignalsClient, &applicationsignals.ListServiceDependenciesInput{
+		StartTime:     &timeRange.From,
+		EndTime:       &timeRange.To,
+		KeyAttributes: serviceMap,
+	})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		for _, dependency := range page.ServiceDependencies {
+			if queryData.OperationName != "" && Dereference(dependency.OperationName) != queryData.OperationName {
+				continue
+			}
+			if dependencyMap != nil && !maps.Equal(dependency.DependencyKeyAttributes, dependencyMap) {
+				continue
+			}
+			if queryData.DependencyOperationName != "" && Dereference(dependency.DependencyOperationName) != queryData.DependencyOperationName {
+				continue
+			}
+			for _, reference := range dependency.MetricReferences {
+				if !strings.EqualFold(Dereference(reference.MetricType), queryData.MetricType) {
+					continue
+				}
+				metrics = append(metrics, serviceMetric{
+					labels: data.Labels{
+						"operation":           Dereference(dependency.OperationName),
+						"dependency":          dependency.DependencyKeyAttributes["Name"],
+						"dependencyOperation": Dereference(dependency.DependencyOperationName),
+					},
+					reference: reference,
+				})
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// getServiceMetricsData gets the values of the metrics with GetMetricData and returns a frame for each metric.
+func getServiceMetricsData(ctx context.Context, cloudWatchClient CloudWatchClient, metrics []serviceMetric, query backend.DataQuery, queryData *GetServiceMetricsQueryData) ([]*data.Frame, error) {
+	statistic := queryData.Statistic
+	if statistic == "" {
+		statistic = "Sum"
+		if strings.EqualFold(queryData.MetricType, "Latency") {
+			statistic = "Average"
+		}
+	}
+	period := metricDataPeriod(query.TimeRange.From, query.Interval)
+
+	frames := make([]*data.Frame, 0, len(metrics))
+	for start := 0; start < len(metrics); start += maxMetricDataQueries {
+		end := min(start+maxMetricDataQueries, len(metrics))
+
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime: &query.TimeRange.From,
+			EndTime:   &query.TimeRange.To,
+			ScanBy:    cloudWatchTypes.ScanByTimestampAscending,
+		}
+		for i, metric := range metrics[start:end] {
+			input.MetricDataQueries = append(input.MetricDataQueries, metricReferenceDataQuery(i, metric.reference, period, statistic))
+		}
+
+		// The values of a metric can be split across pages
+		timestamps := make([][]time.Time, end-start)
+		values := make([][]float64, end-start)
+		for i := range values {
+			timestamps[i], values[i] = []time.Time{}, []float64{}
+		}
+		pager := cloudwatch.NewGetMetricDataPaginator(cloudWatchClient, input)
+		for pager.HasMorePages() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, backend.DownstreamError(err)
+			}
+			for _, result := range page.MetricDataResults {
+				var i int
+				if _, err := fmt.Sscanf(Dereference(result.Id), "m%d", &i); err != nil || i < 0 || i >= end-start {
+					continue
+				}
+				timestamps[i] = append(timestamps[i], result.Timestamps...)
+				values[i] = append(values[i], result.Values...)
+			}
+		}
+
+		for i, metric := range metrics[start:end] {
+			valueField := data.NewField(Dereference(metric.reference.MetricName), metric.labels, values[i])
+			if strings.EqualFold(queryData.MetricType, "Latency") {
+				valueField.SetConfig(&data.FieldConfig{Unit: "ms"})
+			}
+			frames = append(frames, data.NewFrame(
+				Dereference(metric.reference.MetricName),
+				data.NewField("time", nil, timestamps[i]),
+				valueField,
+			))
+		}
+	}
+	return frames, nil
+}
+
+// metricDataPeriod returns the period in seconds rounded up to the resolution CloudWatch keeps for data from the start
+// time. GetMetricData only accepts periods which are multiples of 60 seconds for data up to 15 days old, of 300 seconds
+// for data up to 63 days old and of 3600 seconds for older data.
+func metricDataPeriod(startTime time.Time, period time.Duration) int32 {
+	resolution := time.Minute
+	age := time.Since(startTime)
+	if age > 63*24*time.Hour {
+		resolution = time.Hour
+	} else if age > 15*24*time.Hour {
+		resolution = 5 * time.Minute
+	}
+	rounded := period.Truncate(resolution)
+	if rounded < period || rounded == 0 {
+		rounded += resolution
+	}
+	return int32(rounded.Seconds())
+}
+
+// metricReferenceDataQuery returns the GetMetricData query for a metric referenced by Application Signals. The id of
+// the query is "m" followed by the index.
+func metricReferenceDataQuery(index int, reference appSignalsTypes.MetricReference, period int32, statistic string) cloudWatchTypes.MetricDataQuery {
+	dimensions := make([]cloudWatchTypes.Dimension, 0, len(reference.Dimensions))
+	for _, dimension := range reference.Dimensions {
+		dimensions = append(dimensions, cloudWatchTypes.Dimension{Name: dimension.Name, Value: dimension.Value})
+	}
+	return cloudWatchTypes.MetricDataQuery{
+		// Ids have to start with a lowercase letter
+		Id:        aws.String(fmt.Sprintf("m%d", index)),
+		AccountId: reference.AccountId,
+		MetricStat: &cloudWatchTypes.MetricStat{
+			Metric: &cloudWatchTypes.Metric{
+				Namespace:  reference.Namespace,
+				MetricName: reference.MetricName,
+				Dimensions: dimensions,
+			},
+			Period: aws.Int32(period),
+			Stat:   aws.String(statistic),
+		},
+	}
+}
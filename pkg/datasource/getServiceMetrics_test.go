@@ -0,0 +1,20 @@
+package datasource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricDataPeriod(t *testing.T) {
+	day := 24 * time.Hour
+	require.Equal(t, int32(60), metricDataPeriod(time.Now().Add(-time.Hour), 10*time.Second))
+	require.Equal(t, int32(120), metricDataPeriod(time.Now().Add(-time.Hour), 61*time.Second))
+	require.Equal(t, int32(3600), metricDataPeriod(time.Now().Add(-time.Hour), time.Hour))
+	// Older data is only kept in 5 minute and 1 hour periods
+	require.Equal(t, int32(300), metricDataPeriod(time.Now().Add(-20*day), 2*time.Minute))
+	require.Equal(t, int32(600), metricDataPeriod(time.Now().Add(-20*day), 301*time.Second))
+	require.Equal(t, int32(3600), metricDataPeriod(time.Now().Add(-70*day), 2*time.Minute))
+	require.Equal(t, int32(7200), metricDataPeriod(time.Now().Add(-70*day), 61*time.Minute))
+}
@@ -22,7 +22,7 @@ type ServiceType struct {
 
 func TestServices(t *testing.T) {
 	t.Run("when passed a get request it returns a list of all accountIds in the traces in the selected time frame", func(t *testing.T) {
-		ds := datasource.NewDatasource(context.Background(), xrayClientFactory, appSignalsClientFactory, awsds.AWSDatasourceSettings{})
+		ds := datasource.NewDatasource(context.Background(), xrayClientFactory, appSignalsClientFactory, cloudWatchClientFactory, awsds.AWSDatasourceSettings{})
 		req := httptest.NewRequest("GET", "http://example.com/services?startTime=2022-09-23T00:15:14.365Z&endTime=2022-09-23T01:15:14.365Z&accountId=foo", nil)
 		w := httptest.NewRecorder()
 		ds.GetServices(w, req)
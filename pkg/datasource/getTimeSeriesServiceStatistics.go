@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +14,14 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	GroupByService = "service"
+	GroupByEdge    = "edge"
+
+	maxConcurrentStatisticsRequests = 5
 )
 
 type GetTimeSeriesServiceStatisticsQueryData struct {
@@ -21,6 +29,14 @@ type GetTimeSeriesServiceStatisticsQueryData struct {
 	Columns    []string `json:"columns"`
 	Resolution int32    `json:"resolution"`
 	Region     string   `json:"region"`
+
+	// GroupBy returns a separate frame for each service or edge in the service graph of the Group that has statistics
+	// matching the query.
+	GroupBy string           `json:"groupBy,omitempty"`
+	Group   *xraytypes.Group `json:"group,omitempty"`
+
+	// Forecast adds the upper and lower bounds of the fault count X-Ray forecasts for services.
+	Forecast bool `json:"forecast,omitempty"`
 }
 
 type ValueDef struct {
@@ -64,6 +80,62 @@ var valueDefs = []ValueDef{
 	},
 }
 
+const faultCountColumn = "FaultStatistics.TotalCount"
+
+// Columns added when forecast is requested.
+var forecastValueDefs = []ValueDef{
+	{
+		name:      "Forecast.FaultCountHigh",
+		label:     "Fault Count Upper Bound",
+		valueType: []*int64{},
+	},
+	{
+		name:      "Forecast.FaultCountLow",
+		label:     "Fault Count Lower Bound",
+		valueType: []*int64{},
+	},
+}
+
+// Additional computed columns. They are returned only when requested so queries that select all the columns keep
+// returning the same series.
+var computedValueDefs = []ValueDef{
+	{
+		name:      "Computed.P50ResponseTime",
+		label:     "p50 Response Time",
+		valueType: []*float64{},
+	},
+	{
+		name:      "Computed.P90ResponseTime",
+		label:     "p90 Response Time",
+		valueType: []*float64{},
+	},
+	{
+		name:      "Computed.P99ResponseTime",
+		label:     "p99 Response Time",
+		valueType: []*float64{},
+	},
+	{
+		name:      "Computed.ErrorRate",
+		label:     "Error Rate %",
+		valueType: []*float64{},
+	},
+	{
+		name:      "Computed.FaultRate",
+		label:     "Fault Rate %",
+		valueType: []*float64{},
+	},
+	{
+		name:      "Computed.ThrottleRate",
+		label:     "Throttle Rate %",
+		valueType: []*float64{},
+	},
+	{
+		name:      "Computed.RequestsPerSecond",
+		label:     "Requests Per Second",
+		valueType: []*float64{},
+	},
+}
+
 func (ds *Datasource) getTimeSeriesServiceStatisticsForSingleQuery(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
 	queryData := &GetTimeSeriesServiceStatisticsQueryData{}
 	err := json.Unmarshal(query.JSON, queryData)
@@ -72,12 +144,16 @@ func (ds *Datasource) getTimeSeriesServiceStatisticsForSingleQuery(ctx context.C
 		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
 	}
 
+	if queryData.GroupBy != "" && queryData.GroupBy != GroupByService && queryData.GroupBy != GroupByEdge {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("unknown group by: %s", queryData.GroupBy)))
+	}
+
 	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
 	if err != nil {
 		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
 	}
 
-	log.DefaultLogger.Debug("getTimeSeriesServiceStatisticsForSingleQuery", "RefID", query.RefID, "query", queryData.Query)
+	log.DefaultLogger.Debug("getTimeSeriesServiceStatisticsForSingleQuery", "RefID", query.RefID, "query", queryData.Query, "groupBy", queryData.GroupBy)
 
 	// First get the columns user actually wants. There is no query language for this so we filter it here after we get
 	// the response.
@@ -85,33 +161,26 @@ func (ds *Datasource) getTimeSeriesServiceStatisticsForSingleQuery(ctx context.C
 	// Preferred way to select all columns is to send empty array but "all" is here for backward compatibility
 	if len(queryData.Columns) == 0 || queryData.Columns[0] == "all" {
 		// Add all columns
-		requestedColumns = valueDefs
+		requestedColumns = slices.Clone(valueDefs)
 	} else {
 		valueDefMap := make(map[string]ValueDef)
-		for _, val := range valueDefs {
+		for _, val := range slices.Concat(valueDefs, computedValueDefs) {
 			valueDefMap[val.name] = val
 		}
 
 		for _, name := range queryData.Columns {
-			requestedColumns = append(requestedColumns, ValueDef{
-				name:      name,
-				label:     valueDefMap[name].label,
-				valueType: valueDefMap[name].valueType,
-			})
+			valueDef, ok := valueDefMap[name]
+			if !ok {
+				return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("unknown column: %s", name)))
+			}
+			requestedColumns = append(requestedColumns, valueDef)
 		}
 	}
-
-	// Create the data frames. Separate dataframe for each column. Not 100% this is needed to show each as separate
-	// series.
-	// TODO: check if it isn't simpler to create one dataframe with all the columns
-	var frames []*data.Frame
-	for _, value := range requestedColumns {
-		frames = append(frames, data.NewFrame(
-			"",
-			// This needs to be called time so the default join in Explore works and knows which column to join on.
-			data.NewField("Time", nil, []*time.Time{}),
-			data.NewField(value.label, nil, value.valueType),
-		))
+	if queryData.Forecast {
+		if !slices.ContainsFunc(requestedColumns, func(column ValueDef) bool { return column.name == faultCountColumn }) {
+			requestedColumns = append(requestedColumns, ValueDef{name: faultCountColumn, label: "Fault Count", valueType: []*int64{}})
+		}
+		requestedColumns = append(requestedColumns, forecastValueDefs...)
 	}
 
 	resolution := int32(60)
@@ -119,94 +188,296 @@ func (ds *Datasource) getTimeSeriesServiceStatisticsForSingleQuery(ctx context.C
 		resolution = queryData.Resolution
 	}
 
+	var entities []statisticsEntity
+	if queryData.GroupBy == "" {
+		kind, labels := parseEntitySelector(queryData.Query)
+		entities = []statisticsEntity{{selector: queryData.Query, kind: kind, labels: labels}}
+	} else {
+		services, err := getServiceGraph(ctx, xrayClient, query.TimeRange.From, query.TimeRange.To, &GetServiceMapQueryData{Group: queryData.Group})
+		if err != nil {
+			return backend.ErrorResponseWithErrorSource(err)
+		}
+		entities = groupByEntities(services, queryData.GroupBy, queryData.Query)
+	}
+
+	// Each entity needs a separate request as the API aggregates all the entities matched by the selector and does not
+	// say which entity the statistics belong to. With group by, the entities come from the service graph of the group
+	// so their statistics are limited to the group too.
+	var statisticsGroup *xraytypes.Group
+	if queryData.GroupBy != "" {
+		statisticsGroup = queryData.Group
+	}
+	results := make([][]statisticsBucket, len(entities))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentStatisticsRequests)
+	for i, entity := range entities {
+		group.Go(func() error {
+			buckets, err := getStatisticsBuckets(groupCtx, xrayClient, query.TimeRange, resolution, queryData.Forecast, statisticsGroup, entity)
+			results[i] = buckets
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	var frames []*data.Frame
+	for i, entity := range entities {
+		// With group by, entities without any statistics are not matched by the query so we skip them.
+		if queryData.GroupBy != "" && len(results[i]) == 0 {
+			continue
+		}
+		frames = append(frames, statisticsFrame(results[i], requestedColumns, entity.labels, resolution))
+	}
+
+	return backend.DataResponse{
+		Frames: frames,
+	}
+}
+
+// statisticsEntity is a service or an edge to get the statistics for.
+type statisticsEntity struct {
+	selector string
+	// kind is either GroupByService or GroupByEdge, or empty if it is not known from the selector.
+	kind   string
+	labels data.Labels
+}
+
+// statisticsBucket holds the statistics of all the rows returned for the same time bucket.
+type statisticsBucket struct {
+	timestamp time.Time
+	stats     summaryStatistics
+	histogram []xraytypes.HistogramEntry
+	// Forecast bounds are nil when X-Ray did not return a forecast for the time bucket.
+	faultCountHigh *int64
+	faultCountLow  *int64
+}
+
+// getStatisticsBuckets returns the statistics for the entity sorted by time. The API returns both service and edge
+// statistics, so we use the ones that match the entity and sum the rows with the same timestamp. The statistics are
+// limited to the group if it is set.
+func getStatisticsBuckets(ctx context.Context, xrayClient XrayClient, timeRange backend.TimeRange, resolution int32, forecast bool, group *xraytypes.Group, entity statisticsEntity) ([]statisticsBucket, error) {
 	// Make sure we do not send empty string as that is validation error in x-ray API.
 	var entitySelectorExpression *string
-	if queryData.Query != "" {
-		entitySelectorExpression = &queryData.Query
+	if entity.selector != "" {
+		entitySelectorExpression = &entity.selector
 	}
 
 	request := &xray.GetTimeSeriesServiceStatisticsInput{
-		StartTime:                &query.TimeRange.From,
-		EndTime:                  &query.TimeRange.To,
+		StartTime:                &timeRange.From,
+		EndTime:                  &timeRange.To,
 		EntitySelectorExpression: entitySelectorExpression,
 		Period:                   &resolution,
 	}
+	if forecast {
+		request.ForecastStatistics = aws.Bool(true)
+	}
+	if group != nil {
+		request.GroupName = group.GroupName
+	}
+
+	serviceBuckets := make(map[time.Time]*statisticsBucket)
+	edgeBuckets := make(map[time.Time]*statisticsBucket)
 	pager := xray.NewGetTimeSeriesServiceStatisticsPaginator(xrayClient, request)
-	var pagerError error
 	for pager.HasMorePages() {
 		page, err := pager.NextPage(ctx)
 		if err != nil {
-			pagerError = err
-			break
+			return nil, backend.DownstreamError(err)
 		}
 		for _, statistics := range page.TimeSeriesServiceStatistics {
-			// Use reflection to append values to correct data frame based on the requested columns.
-			for i, column := range requestedColumns {
-				var val reflect.Value
-
-				// There seems to be cases when EdgeSummaryStatistics is nil. Not sure why it does not seem to be the case
-				// in x-ray console so it is being investigated
-				if statistics.EdgeSummaryStatistics != nil {
-					val = reflect.ValueOf(statistics.EdgeSummaryStatistics)
-				} else if statistics.ServiceSummaryStatistics != nil {
-					val = reflect.ValueOf(statistics.ServiceSummaryStatistics)
-				} else {
-					// Hope this should not happen but I think skipping the row in that case should be safe for now.
-					continue
-				}
-
-				parts := strings.Split(column.name, ".")
-				if parts[0] == "Computed" {
-					val = reflect.ValueOf(computeAggregation(parts[1], val.Interface()))
-				} else {
-					for _, part := range parts {
-						val = reflect.Indirect(val).FieldByName(part)
-					}
-				}
-
-				frames[i].AppendRow(
-					statistics.Timestamp,
-					val.Interface(),
-				)
+			if statistics.Timestamp == nil {
+				continue
+			}
+			// There seems to be cases when EdgeSummaryStatistics is nil. Not sure why it does not seem to be the case
+			// in x-ray console so it is being investigated. A row can also have both, then it counts for both kinds.
+			if statistics.EdgeSummaryStatistics != nil {
+				addToBucket(edgeBuckets, *statistics.Timestamp, fromEdgeStatistics(statistics.EdgeSummaryStatistics), statistics.ResponseTimeHistogram, statistics.ServiceForecastStatistics)
+			}
+			// Forecasts are for services and can come in rows without any statistics, for example for time buckets with
+			// no requests.
+			if statistics.ServiceSummaryStatistics != nil || (statistics.EdgeSummaryStatistics == nil && statistics.ServiceForecastStatistics != nil) {
+				addToBucket(serviceBuckets, *statistics.Timestamp, fromServiceStatistics(statistics.ServiceSummaryStatistics), statistics.ResponseTimeHistogram, statistics.ServiceForecastStatistics)
 			}
 		}
 	}
 
-	if pagerError != nil {
-		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(pagerError))
+	buckets := serviceBuckets
+	if entity.kind == GroupByEdge || (entity.kind == "" && len(serviceBuckets) == 0) {
+		buckets = edgeBuckets
 	}
 
-	return backend.DataResponse{
-		Frames: frames,
+	var sorted []statisticsBucket
+	for _, bucket := range buckets {
+		sorted = append(sorted, *bucket)
+	}
+	slices.SortFunc(sorted, func(a, b statisticsBucket) int {
+		return a.timestamp.Compare(b.timestamp)
+	})
+	return sorted, nil
+}
+
+func addToBucket(buckets map[time.Time]*statisticsBucket, timestamp time.Time, stats summaryStatistics, histogram []xraytypes.HistogramEntry, forecast *xraytypes.ForecastStatistics) {
+	bucket, ok := buckets[timestamp]
+	if !ok {
+		bucket = &statisticsBucket{timestamp: timestamp}
+		buckets[timestamp] = bucket
+	}
+	bucket.stats = bucket.stats.add(stats)
+	bucket.histogram = mergeHistograms(bucket.histogram, histogram)
+	if forecast != nil {
+		bucket.faultCountHigh = addInt64(bucket.faultCountHigh, forecast.FaultCountHigh)
+		bucket.faultCountLow = addInt64(bucket.faultCountLow, forecast.FaultCountLow)
+	}
+}
+
+// statisticsFrame returns a wide frame with time and a field for each of the columns.
+func statisticsFrame(buckets []statisticsBucket, columns []ValueDef, labels data.Labels, resolution int32) *data.Frame {
+	frame := data.NewFrame("",
+		// This needs to be called time so the default join in Explore works and knows which column to join on.
+		data.NewField("Time", nil, []*time.Time{}),
+	)
+	for _, column := range columns {
+		frame.Fields = append(frame.Fields, data.NewField(column.label, labels, column.valueType))
+	}
+
+	for _, bucket := range buckets {
+		row := []interface{}{aws.Time(bucket.timestamp)}
+		for _, column := range columns {
+			parts := strings.Split(column.name, ".")
+			if parts[0] == "Computed" {
+				row = append(row, computeAggregation(parts[1], bucket.stats, bucket.histogram, resolution))
+			} else if parts[0] == "Forecast" {
+				row = append(row, forecastValue(parts[1], bucket))
+			} else {
+				row = append(row, countValue(column.name, bucket.stats))
+			}
+		}
+		frame.AppendRow(row...)
+	}
+	return frame
+}
+
+// countValue returns the value of one of the count columns.
+func countValue(name string, stats summaryStatistics) *int64 {
+	switch name {
+	case "ErrorStatistics.ThrottleCount":
+		return aws.Int64(stats.throttleCount)
+	case "ErrorStatistics.TotalCount":
+		return aws.Int64(stats.errorCount)
+	case "FaultStatistics.TotalCount":
+		return aws.Int64(stats.faultCount)
+	case "OkCount":
+		return aws.Int64(stats.okCount)
+	case "TotalCount":
+		return aws.Int64(stats.totalCount)
+	default:
+		return nil
 	}
 }
 
-// computeAggregation computes new values on top of the API.
-// values is an interface{} because it can be either EdgeStatistics or ServiceStatistics and they are
-// the same but are different types.
-func computeAggregation(name string, values interface{}) interface{} {
+// forecastValue returns one of the fault count bounds X-Ray forecasts for the time bucket.
+func forecastValue(name string, bucket statisticsBucket) *int64 {
+	switch name {
+	case "FaultCountHigh":
+		return bucket.faultCountHigh
+	case "FaultCountLow":
+		return bucket.faultCountLow
+	default:
+		return nil
+	}
+}
+
+// computeAggregation computes new values on top of the API. Column names are validated before so the default case
+// should not happen. Values are nil when there were no requests in the time bucket.
+func computeAggregation(name string, stats summaryStatistics, histogram []xraytypes.HistogramEntry, period int32) *float64 {
+	if stats.totalCount == 0 {
+		return nil
+	}
 	switch name {
 	case "AverageResponseTime":
-		return aws.Float64(*getResponseTime(values) / float64(*getTotalCount(values)))
+		return aws.Float64(stats.totalResponseTime / float64(stats.totalCount))
+	case "P50ResponseTime":
+		return histogramPercentile(histogram, 50)
+	case "P90ResponseTime":
+		return histogramPercentile(histogram, 90)
+	case "P99ResponseTime":
+		return histogramPercentile(histogram, 99)
+	case "ErrorRate":
+		return aws.Float64(stats.rate(stats.errorCount) * 100)
+	case "FaultRate":
+		return aws.Float64(stats.faultRate() * 100)
+	case "ThrottleRate":
+		return aws.Float64(stats.throttleRate() * 100)
+	case "RequestsPerSecond":
+		return aws.Float64(float64(stats.totalCount) / float64(period))
 	default:
-		panic(fmt.Sprintf("Unknown computed column: %s", name))
+		return nil
 	}
 }
 
-func getResponseTime(stats interface{}) *float64 {
-	if val, ok := stats.(*xraytypes.EdgeStatistics); ok {
-		return val.TotalResponseTime
-	} else if val, ok := stats.(*xraytypes.ServiceStatistics); ok {
-		return val.TotalResponseTime
+// groupByEntities returns a service or edge entity for each of the services or edges in the service graph. Client nodes
+// are skipped as they are not services. Selectors include the account so services with the same name in different
+// accounts are kept apart. The query, if any, is added to each selector so only the matching statistics
+// are returned.
+func groupByEntities(services []xraytypes.Service, groupBy string, query string) []statisticsEntity {
+	servicesByReferenceId := make(map[int32]xraytypes.Service)
+	for _, service := range services {
+		servicesByReferenceId[Dereference(service.ReferenceId)] = service
 	}
-	panic("stats is not xray.EdgeStatistics nor xray.ServiceStatistics")
+
+	var entities []statisticsEntity
+	seen := make(map[string]bool)
+	add := func(entity statisticsEntity) {
+		if seen[entity.selector] {
+			return
+		}
+		seen[entity.selector] = true
+		if query != "" {
+			entity.selector = fmt.Sprintf("%s AND (%s)", entity.selector, query)
+		}
+		entities = append(entities, entity)
+	}
+
+	for _, service := range services {
+		if Dereference(service.Type) == clientNodeType {
+			continue
+		}
+		source := serviceSelector(Dereference(service.Name), Dereference(service.Type), Dereference(service.AccountId))
+		if groupBy == GroupByService {
+			add(statisticsEntity{
+				selector: fmt.Sprintf("service(%s)", source),
+				kind:     GroupByService,
+				labels:   serviceLabels("", service),
+			})
+			continue
+		}
+		for _, edge := range service.Edges {
+			target, ok := servicesByReferenceId[Dereference(edge.ReferenceId)]
+			if !ok {
+				continue
+			}
+			labels := serviceLabels("source", service)
+			for key, value := range serviceLabels("target", target) {
+				labels[key] = value
+			}
+			add(statisticsEntity{
+				selector: fmt.Sprintf("edge(%s, %s)", source, serviceSelector(Dereference(target.Name), Dereference(target.Type), Dereference(target.AccountId))),
+				kind:     GroupByEdge,
+				labels:   labels,
+			})
+		}
+	}
+	return entities
 }
 
-func getTotalCount(stats interface{}) *int64 {
-	if val, ok := stats.(*xraytypes.EdgeStatistics); ok {
-		return val.TotalCount
-	} else if val, ok := stats.(*xraytypes.ServiceStatistics); ok {
-		return val.TotalCount
+// serviceLabels returns name, type and account labels of the service, with the prefix if it is not empty.
+func serviceLabels(prefix string, service xraytypes.Service) data.Labels {
+	labels := data.Labels{labelKey(prefix, "service"): Dereference(service.Name)}
+	if service.Type != nil {
+		labels[labelKey(prefix, "type")] = *service.Type
+	}
+	if service.AccountId != nil {
+		labels[labelKey(prefix, "account")] = *service.AccountId
 	}
-	panic("stats is not xray.EdgeStatistics nor xray.ServiceStatistics")
+	return labels
 }
@@ -0,0 +1,134 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type GetTraceRateQueryData struct {
+	Query  string           `json:"query"`
+	Group  *xraytypes.Group `json:"group"`
+	Region string           `json:"region"`
+	// Resolution is the size of the time buckets in seconds. Query interval is used if it is not set.
+	Resolution int32 `json:"resolution,omitempty"`
+}
+
+// traceRateCounts are the counts of traces in a single time bucket.
+type traceRateCounts struct {
+	total    int64
+	errors   int64
+	faults   int64
+	throttle int64
+}
+
+// getSingleTraceRate returns the count of traces matching the filter expression in each time bucket. Unlike the
+// Trace Statistics query it works with any filter expression as it counts the trace summaries. If there are too many
+// traces only a sample of them is fetched and the counts are estimated from it.
+func (ds *Datasource) getSingleTraceRate(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetTraceRateQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleTraceRate", "RefID", query.RefID, "query", queryData.Query)
+
+	const maxTraces = 10000
+	traces, sampling, err := ds.getTraceSummariesData(ctx, query, maxTraces, pluginContext)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	interval := traceRateInterval(query, queryData)
+	frame := traceRateFrame(traces, query.TimeRange, interval, sampling)
+	if sampling < 1 {
+		frame.Meta.Notices = append(frame.Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("Counts are estimated from a sample of %.2f%% of the traces.", sampling*100),
+		})
+	}
+
+	return backend.DataResponse{
+		Frames: []*data.Frame{frame},
+	}
+}
+
+// Maximum number of time buckets, used when the query does not set a lower MaxDataPoints.
+const maxTraceRateBuckets = 11000
+
+// traceRateInterval returns the size of the time buckets. Trace start times are in seconds so there is no point in
+// smaller buckets. The interval is increased so the time range does not have more buckets than MaxDataPoints, as
+// every bucket is returned even if it is empty.
+func traceRateInterval(query backend.DataQuery, queryData *GetTraceRateQueryData) time.Duration {
+	interval := query.Interval
+	if queryData.Resolution > 0 {
+		interval = time.Duration(queryData.Resolution) * time.Second
+	}
+
+	maxBuckets := int64(maxTraceRateBuckets)
+	if query.MaxDataPoints > 0 && query.MaxDataPoints < maxBuckets {
+		maxBuckets = query.MaxDataPoints
+	}
+	minInterval := query.TimeRange.Duration() / time.Duration(maxBuckets)
+	if minInterval%time.Second != 0 {
+		minInterval = minInterval.Truncate(time.Second) + time.Second
+	}
+	return max(interval, minInterval, time.Second)
+}
+
+// traceRateFrame counts the traces in each time bucket and divides the counts by sampling to estimate the counts of
+// all the traces. Buckets without traces are included with zero counts so gaps do not look like missing data.
+func traceRateFrame(traces []xraytypes.TraceSummary, timeRange backend.TimeRange, interval time.Duration, sampling float64) *data.Frame {
+	buckets := make(map[time.Time]*traceRateCounts)
+	for _, trace := range traces {
+		if trace.StartTime == nil {
+			continue
+		}
+		bucketTime := trace.StartTime.Truncate(interval)
+		counts, ok := buckets[bucketTime]
+		if !ok {
+			counts = &traceRateCounts{}
+			buckets[bucketTime] = counts
+		}
+		counts.total++
+		if Dereference(trace.HasError) {
+			counts.errors++
+		}
+		if Dereference(trace.HasFault) {
+			counts.faults++
+		}
+		if Dereference(trace.HasThrottle) {
+			counts.throttle++
+		}
+	}
+
+	frame := data.NewFrame(
+		"TraceRate",
+		// This needs to be called time so the default join in Explore works and knows which column to join on.
+		data.NewField("Time", nil, []time.Time{}),
+		data.NewField("Total Count", nil, []float64{}),
+		data.NewField("Error Count", nil, []float64{}),
+		data.NewField("Fault Count", nil, []float64{}),
+		data.NewField("Throttle Count", nil, []float64{}),
+	)
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesWide}
+
+	estimate := func(count int64) float64 {
+		return float64(count) / sampling
+	}
+	for bucketTime := timeRange.From.Truncate(interval); bucketTime.Before(timeRange.To); bucketTime = bucketTime.Add(interval) {
+		counts, ok := buckets[bucketTime]
+		if !ok {
+			counts = &traceRateCounts{}
+		}
+		frame.AppendRow(bucketTime, estimate(counts.total), estimate(counts.errors), estimate(counts.faults), estimate(counts.throttle))
+	}
+	return frame
+}
@@ -0,0 +1,56 @@
+package datasource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceRateFrame(t *testing.T) {
+	from := time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(3 * time.Minute)}
+	traces := []xraytypes.TraceSummary{
+		{StartTime: aws.Time(from.Add(10 * time.Second)), HasError: aws.Bool(true)},
+		{StartTime: aws.Time(from.Add(50 * time.Second)), HasFault: aws.Bool(true)},
+		{StartTime: aws.Time(from.Add(130 * time.Second)), HasThrottle: aws.Bool(true), HasError: aws.Bool(true)},
+		// Skipped as it cannot be bucketed
+		{HasFault: aws.Bool(true)},
+	}
+
+	frame := traceRateFrame(traces, timeRange, time.Minute, 0.5)
+	require.Equal(t, 3, frame.Rows())
+
+	require.Equal(t, from, frame.Fields[0].At(0))
+	require.Equal(t, float64(4), frame.Fields[1].At(0))
+	require.Equal(t, float64(2), frame.Fields[2].At(0))
+	require.Equal(t, float64(2), frame.Fields[3].At(0))
+	require.Equal(t, float64(0), frame.Fields[4].At(0))
+
+	// Empty bucket is filled with zeros
+	require.Equal(t, from.Add(time.Minute), frame.Fields[0].At(1))
+	require.Equal(t, float64(0), frame.Fields[1].At(1))
+
+	require.Equal(t, float64(2), frame.Fields[1].At(2))
+	require.Equal(t, float64(2), frame.Fields[2].At(2))
+	require.Equal(t, float64(2), frame.Fields[4].At(2))
+}
+
+func TestTraceRateInterval(t *testing.T) {
+	query := backend.DataQuery{Interval: 30 * time.Second}
+	require.Equal(t, 30*time.Second, traceRateInterval(query, &GetTraceRateQueryData{}))
+	require.Equal(t, 5*time.Minute, traceRateInterval(query, &GetTraceRateQueryData{Resolution: 300}))
+	require.Equal(t, time.Second, traceRateInterval(backend.DataQuery{}, &GetTraceRateQueryData{}))
+
+	from := time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)
+	// 7 days in at most 1000 buckets need buckets of 605 seconds, rounded up to whole seconds
+	query = backend.DataQuery{Interval: time.Second, MaxDataPoints: 1000, TimeRange: backend.TimeRange{From: from, To: from.Add(7 * 24 * time.Hour)}}
+	require.Equal(t, 605*time.Second, traceRateInterval(query, &GetTraceRateQueryData{}))
+	require.Equal(t, time.Hour, traceRateInterval(query, &GetTraceRateQueryData{Resolution: 3600}))
+	// Without MaxDataPoints the number of buckets is still limited
+	query.MaxDataPoints = 0
+	require.Equal(t, 55*time.Second, traceRateInterval(query, &GetTraceRateQueryData{}))
+}
@@ -0,0 +1,223 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const defaultTransactionSearchLimit = 5
+
+// StartTraceRetrieval accepts at most 5 trace ids and the retrieved traces share one trace graph.
+const maxTransactionSearchLimit = 5
+
+// How long to wait between checks of the trace retrieval status. It is a variable so tests do not have to wait.
+var traceRetrievalPollInterval = time.Second
+
+type GetTransactionSearchQueryData struct {
+	Query  string `json:"query"`
+	Region string `json:"region"`
+	// Limit is the maximum number of traces to retrieve, at most maxTransactionSearchLimit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// getSingleTransactionSearch returns complete traces whose spans are stored in CloudWatch Logs with Transaction Search.
+// GetTraceSummaries only returns the indexed traces, so it is used to find the trace ids matching the filter expression
+// and the spans of those traces are then retrieved from CloudWatch Logs. Retrieval is asynchronous, the query polls
+// until it is done or the request is cancelled.
+func (ds *Datasource) getSingleTransactionSearch(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &GetTransactionSearchQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+	if queryData.Limit <= 0 {
+		queryData.Limit = defaultTransactionSearchLimit
+	}
+	if queryData.Limit > maxTransactionSearchLimit {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("limit can be at most %d", maxTransactionSearchLimit))
+	}
+
+	xrayClient, err := ds.getClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	log.DefaultLogger.Debug("getSingleTransactionSearch", "RefID", query.RefID, "query", queryData.Query, "limit", queryData.Limit)
+
+	traceIds, err := getTraceIds(ctx, xrayClient, query.TimeRange, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+	if len(traceIds) == 0 {
+		return backend.DataResponse{}
+	}
+
+	retrieval, err := xrayClient.StartTraceRetrieval(ctx, &xray.StartTraceRetrievalInput{
+		StartTime: &query.TimeRange.From,
+		EndTime:   &query.TimeRange.To,
+		TraceIds:  traceIds,
+	})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+	}
+
+	traces, err := listRetrievedTraces(ctx, xrayClient, retrieval.RetrievalToken)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	var frames []*data.Frame
+	for _, trace := range traces {
+		// Spans in the X-Ray format are segment documents so the trace can be shown the same way as from getTrace.
+		segments := make([]xraytypes.Segment, 0, len(trace.Spans))
+		for _, span := range trace.Spans {
+			segments = append(segments, xraytypes.Segment{Id: span.Id, Document: span.Document})
+		}
+		traceBytes, err := json.Marshal(xraytypes.Trace{Id: trace.Id, Duration: trace.Duration, Segments: segments})
+		if err != nil {
+			return backend.ErrorResponseWithErrorSource(
+				backend.PluginError(fmt.Errorf("failed to json.Marshal trace \"%s\" :%w", Dereference(trace.Id), err)),
+			)
+		}
+		frames = append(frames, data.NewFrame("Traces", data.NewField("Trace", nil, []string{string(traceBytes)})))
+	}
+
+	// Same as in getTrace, the traces are still returned if the graph fails.
+	response := backend.DataResponse{}
+	traceGraphFrame, err := getRetrievedTracesGraphFrame(ctx, xrayClient, retrieval.RetrievalToken)
+	if err != nil {
+		response = backend.ErrorResponseWithErrorSource(err)
+	} else {
+		frames = append(frames, traceGraphFrame)
+	}
+	response.Frames = frames
+	return response
+}
+
+// getTraceIds returns the ids of up to Limit traces matching the filter expression.
+func getTraceIds(ctx context.Context, xrayClient XrayClient, timeRange backend.TimeRange, queryData *GetTransactionSearchQueryData) ([]string, error) {
+	input := &xray.GetTraceSummariesInput{
+		StartTime: &timeRange.From,
+		EndTime:   &timeRange.To,
+	}
+	if queryData.Query != "" {
+		input.FilterExpression = aws.String(queryData.Query)
+	}
+
+	var traceIds []string
+	pager := xray.NewGetTraceSummariesPaginator(xrayClient, input)
+	for pager.HasMorePages() && len(traceIds) < queryData.Limit {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, backend.DownstreamError(err)
+		}
+		for _, summary := range page.TraceSummaries {
+			if summary.Id == nil || len(traceIds) >= queryData.Limit || slices.Contains(traceIds, *summary.Id) {
+				continue
+			}
+			traceIds = append(traceIds, *summary.Id)
+		}
+	}
+	return traceIds, nil
+}
+
+// listRetrievedTraces waits until the retrieval is complete and returns all the retrieved traces.
+func listRetrievedTraces(ctx context.Context, xrayClient XrayClient, retrievalToken *string) ([]xraytypes.RetrievedTrace, error) {
+	var traces []xraytypes.RetrievedTrace
+	err := waitForTraceRetrieval(ctx, func() (xraytypes.RetrievalStatus, error) {
+		traces = nil
+		input := &xray.ListRetrievedTracesInput{RetrievalToken: retrievalToken, TraceFormat: xraytypes.TraceFormatTypeXray}
+		for {
+			output, err := xrayClient.ListRetrievedTraces(ctx, input)
+			if err != nil {
+				return "", backend.DownstreamError(err)
+			}
+			if output.RetrievalStatus != xraytypes.RetrievalStatusComplete {
+				return output.RetrievalStatus, nil
+			}
+			traces = append(traces, output.Traces...)
+			if output.NextToken == nil || *output.NextToken == "" {
+				return output.RetrievalStatus, nil
+			}
+			input.NextToken = output.NextToken
+		}
+	})
+	return traces, err
+}
+
+// getRetrievedTracesGraphFrame waits until the retrieval is complete and returns the services of the retrieved traces
+// in the same format as the TraceGraph frame of getTrace.
+func getRetrievedTracesGraphFrame(ctx context.Context, xrayClient XrayClient, retrievalToken *string) (*data.Frame, error) {
+	var services []xraytypes.Service
+	err := waitForTraceRetrieval(ctx, func() (xraytypes.RetrievalStatus, error) {
+		services = nil
+		input := &xray.GetRetrievedTracesGraphInput{RetrievalToken: retrievalToken}
+		for {
+			output, err := xrayClient.GetRetrievedTracesGraph(ctx, input)
+			if err != nil {
+				return "", backend.DownstreamError(err)
+			}
+			if output.RetrievalStatus != xraytypes.RetrievalStatusComplete {
+				return output.RetrievalStatus, nil
+			}
+			for _, service := range output.Services {
+				if service.Service != nil {
+					services = append(services, *service.Service)
+				}
+			}
+			if output.NextToken == nil || *output.NextToken == "" {
+				return output.RetrievalStatus, nil
+			}
+			input.NextToken = output.NextToken
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	traceGraphFrame := data.NewFrame(
+		"TraceGraph",
+		data.NewField("Service", nil, []string{}),
+	)
+	for _, service := range services {
+		bytes, err := json.Marshal(service)
+		if err != nil {
+			return nil, backend.PluginError(err)
+		}
+		traceGraphFrame.AppendRow(string(bytes))
+	}
+	return traceGraphFrame, nil
+}
+
+// waitForTraceRetrieval calls check until it returns the complete status. It fails when the retrieval fails or ctx is
+// done before that.
+func waitForTraceRetrieval(ctx context.Context, check func() (xraytypes.RetrievalStatus, error)) error {
+	for {
+		status, err := check()
+		if err != nil {
+			return err
+		}
+		switch status {
+		case xraytypes.RetrievalStatusComplete:
+			return nil
+		case xraytypes.RetrievalStatusFailed, xraytypes.RetrievalStatusCancelled, xraytypes.RetrievalStatusTimeout:
+			return backend.DownstreamError(fmt.Errorf("trace retrieval did not complete: %s", status))
+		}
+
+		select {
+		case <-ctx.Done():
+			return backend.DownstreamError(fmt.Errorf("trace retrieval did not complete: %w", ctx.Err()))
+		case <-time.After(traceRetrievalPollInterval):
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForTraceRetrieval(t *testing.T) {
+	traceRetrievalPollInterval = time.Millisecond
+	defer func() { traceRetrievalPollInterval = time.Second }()
+
+	t.Run("polls until complete", func(t *testing.T) {
+		statuses := []xraytypes.RetrievalStatus{xraytypes.RetrievalStatusScheduled, xraytypes.RetrievalStatusRunning, xraytypes.RetrievalStatusComplete}
+		calls := 0
+		err := waitForTraceRetrieval(context.Background(), func() (xraytypes.RetrievalStatus, error) {
+			calls++
+			return statuses[calls-1], nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := waitForTraceRetrieval(ctx, func() (xraytypes.RetrievalStatus, error) {
+			return xraytypes.RetrievalStatusRunning, nil
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("fails when retrieval times out", func(t *testing.T) {
+		err := waitForTraceRetrieval(context.Background(), func() (xraytypes.RetrievalStatus, error) {
+			return xraytypes.RetrievalStatusTimeout, nil
+		})
+		require.ErrorContains(t, err, "TIMEOUT")
+	})
+}
@@ -3,9 +3,6 @@ package datasource
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"sort"
-	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -16,6 +13,8 @@ import (
 type ListServiceDependenciesQueryData struct {
 	Region        string `json:"region,omitempty"`
 	ServiceString string `json:"serviceString,omitempty"`
+	// AttributesFormat is AttributesFormatString or AttributesFormatJSON.
+	AttributesFormat string `json:"attributesFormat,omitempty"`
 }
 
 func (ds *Datasource) ListServiceDependencies(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
@@ -49,14 +48,14 @@ func (ds *Datasource) ListServiceDependencies(ctx context.Context, query backend
 	var listServiceDependenciesFrame = data.NewFrame(
 		"ListServiceDependencies",
 		data.NewField("OperationName", nil, []*string{}),
-		data.NewField("DependencyKeyAttributes", nil, []*string{}),
+		newAttributesField("DependencyKeyAttributes", queryData.AttributesFormat),
 		data.NewField("DependencyOperationName", nil, []*string{}),
 
 		data.NewField("MetricName", nil, []*string{}),
 		data.NewField("MetricType", nil, []*string{}),
 		data.NewField("Namespace", nil, []*string{}),
 		data.NewField("AccountId", nil, []*string{}),
-		data.NewField("Dimensions", nil, []*string{}),
+		newAttributesField("Dimensions", queryData.AttributesFormat),
 	)
 
 	pager := applicationsignals.NewListServiceDependenciesPaginator(appSignalsClient, &input)
@@ -71,47 +70,19 @@ func (ds *Datasource) ListServiceDependencies(ctx context.Context, query backend
 
 		for _, dependency := range output.ServiceDependencies {
 			for _, metric := range dependency.MetricReferences {
-				dimensions := ""
-				for i, dimension := range metric.Dimensions {
-					if i > 0 {
-						dimensions += " "
-					}
-					dimensions += *dimension.Name + "=\"" + *dimension.Value + "\""
-				}
-				var dimensionsPtr *string
-				if len(dimensions) > 0 {
-					dimensionsPtr = &dimensions
-				}
-
-				// sort the keys to ensure consistent ordering and testability
-				sortedAttributeKeys := make([]string, 0, len(dependency.DependencyKeyAttributes))
-				for k := range dependency.DependencyKeyAttributes {
-					sortedAttributeKeys = append(sortedAttributeKeys, k)
-				}
-				sort.Strings(sortedAttributeKeys)
-
-				// build key:value strings for sorted attributes
-				attributePairs := make([]string, 0, len(dependency.DependencyKeyAttributes))
-				for _, k := range sortedAttributeKeys {
-					attributePairs = append(attributePairs, fmt.Sprintf("%s:%s", k, dependency.DependencyKeyAttributes[k]))
-				}
-
-				finalString := strings.Join(attributePairs, ", ")
-
-				var dependencyKeyAttributesPtr *string
-				if len(finalString) > 0 {
-					dependencyKeyAttributesPtr = &finalString
-				}
+				dimensions := attributesValue(dimensionsMap(metric.Dimensions), queryData.AttributesFormat, dimensionsString(metric.Dimensions))
+
+				dependencyKeyAttributes := attributesValue(dependency.DependencyKeyAttributes, queryData.AttributesFormat, keyAttributesString(dependency.DependencyKeyAttributes))
 
 				listServiceDependenciesFrame.AppendRow(
 					dependency.OperationName,
-					dependencyKeyAttributesPtr,
+					dependencyKeyAttributes,
 					dependency.DependencyOperationName,
 					metric.MetricName,
 					metric.MetricType,
 					metric.Namespace,
 					metric.AccountId,
-					dimensionsPtr,
+					dimensions,
 				)
 			}
 		}
@@ -0,0 +1,100 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+)
+
+type ListServiceDependentsQueryData struct {
+	Region        string `json:"region,omitempty"`
+	ServiceString string `json:"serviceString,omitempty"`
+	// AttributesFormat is AttributesFormatString or AttributesFormatJSON.
+	AttributesFormat string `json:"attributesFormat,omitempty"`
+}
+
+// ListServiceDependents lists the services calling the service, which is the reverse of ListServiceDependencies. The
+// OperationName is the operation of the service that is called.
+func (ds *Datasource) ListServiceDependents(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
+	queryData := &ListServiceDependentsQueryData{}
+	err := json.Unmarshal(query.JSON, queryData)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	if len(queryData.ServiceString) == 0 {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("Service not set on query"))
+	}
+
+	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	serviceMap := map[string]string{}
+	err = json.Unmarshal([]byte(queryData.ServiceString), &serviceMap)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
+	}
+
+	input := applicationsignals.ListServiceDependentsInput{
+		StartTime:     &query.TimeRange.From,
+		EndTime:       &query.TimeRange.To,
+		KeyAttributes: serviceMap,
+	}
+
+	var listServiceDependentsFrame = data.NewFrame(
+		"ListServiceDependents",
+		data.NewField("OperationName", nil, []*string{}),
+		newAttributesField("DependentKeyAttributes", queryData.AttributesFormat),
+		data.NewField("DependentOperationName", nil, []*string{}),
+
+		data.NewField("MetricName", nil, []*string{}),
+		data.NewField("MetricType", nil, []*string{}),
+		data.NewField("Namespace", nil, []*string{}),
+		data.NewField("AccountId", nil, []*string{}),
+		newAttributesField("Dimensions", queryData.AttributesFormat),
+	)
+
+	pager := applicationsignals.NewListServiceDependentsPaginator(appSignalsClient, &input)
+	var pagerError error
+
+	for pager.HasMorePages() {
+		output, err := pager.NextPage(ctx)
+		if err != nil {
+			pagerError = err
+			break
+		}
+
+		for _, dependent := range output.ServiceDependents {
+			for _, metric := range dependent.MetricReferences {
+				dimensions := attributesValue(dimensionsMap(metric.Dimensions), queryData.AttributesFormat, dimensionsString(metric.Dimensions))
+
+				dependentKeyAttributes := attributesValue(dependent.DependentKeyAttributes, queryData.AttributesFormat, keyAttributesString(dependent.DependentKeyAttributes))
+
+				listServiceDependentsFrame.AppendRow(
+					dependent.OperationName,
+					dependentKeyAttributes,
+					dependent.DependentOperationName,
+					metric.MetricName,
+					metric.MetricType,
+					metric.Namespace,
+					metric.AccountId,
+					dimensions,
+				)
+			}
+		}
+
+	}
+	if pagerError != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(pagerError))
+	}
+
+	return backend.DataResponse{
+		Frames: data.Frames{listServiceDependentsFrame},
+	}
+}
@@ -13,16 +13,31 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
 )
 
 type ListServiceLevelObjectivesQueryData struct {
-	Region                string `json:"region,omitempty"`
+	Region string `json:"region,omitempty"`
+	// Service and operation are optional, all the SLOs in the account are listed without them.
 	ServiceString         string `json:"serviceString,omitempty"`
 	OperationName         string `json:"operationName,omitempty"`
 	IncludeLinkedAccounts bool   `json:"includeLinkedAccounts,omitempty"`
 	AccountId             string `json:"accountId,omitempty"`
+
+	// Only list the SLOs of these metric source types, for example ServiceOperation or ServiceDependency.
+	MetricSourceTypes []string `json:"metricSourceTypes,omitempty"`
+	// Only list the SLOs of a dependency, DependencyString has the key attributes of the dependency in the same format
+	// as ServiceString.
+	DependencyString        string `json:"dependencyString,omitempty"`
+	DependencyOperationName string `json:"dependencyOperationName,omitempty"`
+
+	// AttributesFormat is AttributesFormatString or AttributesFormatJSON.
+	AttributesFormat string `json:"attributesFormat,omitempty"`
 }
 
+// sloKeyAttributes are the key attributes of the service that get their own column in the SLO list.
+var sloKeyAttributes = []string{"Type", "ResourceType", "Name", "Identifier", "Environment", "AwsAccountId"}
+
 func (ds *Datasource) ListServiceLevelObjectives(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
 	queryData := &ListServiceLevelObjectivesQueryData{}
 	err := json.Unmarshal(query.JSON, queryData)
@@ -30,38 +45,14 @@ func (ds *Datasource) ListServiceLevelObjectives(ctx context.Context, query back
 		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
 	}
 
-	if len(queryData.ServiceString) == 0 {
-		return backend.ErrorResponseWithErrorSource(backend.DownstreamErrorf("Service not set on query"))
-	}
-
 	appSignalsClient, err := ds.getAppSignalsClient(ctx, pluginContext, RequestSettings{Region: queryData.Region})
 	if err != nil {
 		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
 	}
 
-	serviceMap := map[string]string{}
-	err = json.Unmarshal([]byte(queryData.ServiceString), &serviceMap)
+	input, err := listServiceLevelObjectivesInput(queryData)
 	if err != nil {
-		return backend.ErrorResponseWithErrorSource(backend.PluginError(err))
-	}
-
-	input := applicationsignals.ListServiceLevelObjectivesInput{
-		OperationName:         aws.String(queryData.OperationName),
-		KeyAttributes:         serviceMap,
-		IncludeLinkedAccounts: queryData.IncludeLinkedAccounts,
-	}
-
-	if input.IncludeLinkedAccounts {
-		// only replace the value if accountId is set on the query
-		if queryData.AccountId != "" && queryData.AccountId != "all" {
-			input.KeyAttributes["AwsAccountId"] = queryData.AccountId
-		}
-	} else {
-		if input.KeyAttributes["AwsAccountId"] != "" {
-			// only include accountId attribute of the service if IncludeLinkedAccounts is true
-			delete(input.KeyAttributes, "AwsAccountId")
-		}
-
+		return backend.ErrorResponseWithErrorSource(err)
 	}
 
 	var listSLOsFrame = data.NewFrame(
@@ -69,10 +60,21 @@ func (ds *Datasource) ListServiceLevelObjectives(ctx context.Context, query back
 		data.NewField("Name", nil, []*string{}),
 		data.NewField("OperationName", nil, []*string{}),
 		data.NewField("CreatedTime", nil, []*time.Time{}),
-		data.NewField("KeyAttributes", nil, []*string{}),
+		newAttributesField("KeyAttributes", queryData.AttributesFormat),
+		data.NewField("ServiceType", nil, []string{}),
+		data.NewField("ServiceResourceType", nil, []string{}),
+		data.NewField("ServiceName", nil, []string{}),
+		data.NewField("ServiceIdentifier", nil, []string{}),
+		data.NewField("ServiceEnvironment", nil, []string{}),
+		data.NewField("AwsAccountId", nil, []string{}),
+		data.NewField("MetricSourceType", nil, []string{}),
+		data.NewField("EvaluationType", nil, []string{}),
+		newAttributesField("DependencyKeyAttributes", queryData.AttributesFormat),
+		data.NewField("DependencyOperationName", nil, []*string{}),
+		data.NewField("Arn", nil, []*string{}),
 	)
 
-	pager := applicationsignals.NewListServiceLevelObjectivesPaginator(appSignalsClient, &input)
+	pager := applicationsignals.NewListServiceLevelObjectivesPaginator(appSignalsClient, input)
 	var pagerError error
 
 	for pager.HasMorePages() {
@@ -83,32 +85,32 @@ func (ds *Datasource) ListServiceLevelObjectives(ctx context.Context, query back
 		}
 
 		for _, sloSummary := range output.SloSummaries {
-			// sort the keys to ensure consistent ordering and testability
-			sortedAttributeKeys := make([]string, 0, len(sloSummary.KeyAttributes))
-			for k := range sloSummary.KeyAttributes {
-				sortedAttributeKeys = append(sortedAttributeKeys, k)
-			}
-			sort.Strings(sortedAttributeKeys)
-
-			// build key:value strings for sorted attributes
-			attributePairs := make([]string, 0, len(sloSummary.KeyAttributes))
-			for _, k := range sortedAttributeKeys {
-				attributePairs = append(attributePairs, fmt.Sprintf("%s:%s", k, sloSummary.KeyAttributes[k]))
-			}
-
-			finalString := strings.Join(attributePairs, ", ")
-
-			var sloAttrPairsPtr *string
-			if len(finalString) > 0 {
-				sloAttrPairsPtr = aws.String(finalString)
+			var dependencyKeyAttributesMap map[string]string
+			var dependencyOperationName *string
+			if sloSummary.DependencyConfig != nil {
+				dependencyKeyAttributesMap = sloSummary.DependencyConfig.DependencyKeyAttributes
+				dependencyOperationName = sloSummary.DependencyConfig.DependencyOperationName
 			}
+			keyAttributes := attributesValue(sloSummary.KeyAttributes, queryData.AttributesFormat, keyAttributesString(sloSummary.KeyAttributes))
+			dependencyKeyAttributes := attributesValue(dependencyKeyAttributesMap, queryData.AttributesFormat, keyAttributesString(dependencyKeyAttributesMap))
 
-			listSLOsFrame.AppendRow(
+			row := []any{
 				sloSummary.Name,
 				sloSummary.OperationName,
 				sloSummary.CreatedTime,
-				sloAttrPairsPtr,
+				keyAttributes,
+			}
+			for _, key := range sloKeyAttributes {
+				row = append(row, sloSummary.KeyAttributes[key])
+			}
+			row = append(row,
+				string(sloSummary.MetricSourceType),
+				string(sloSummary.EvaluationType),
+				dependencyKeyAttributes,
+				dependencyOperationName,
+				sloSummary.Arn,
 			)
+			listSLOsFrame.AppendRow(row...)
 		}
 
 	}
@@ -120,3 +122,79 @@ func (ds *Datasource) ListServiceLevelObjectives(ctx context.Context, query back
 		Frames: data.Frames{listSLOsFrame},
 	}
 }
+
+// listServiceLevelObjectivesInput builds the ListServiceLevelObjectives input from the query, only the filters that are
+// set on the query are sent.
+func listServiceLevelObjectivesInput(queryData *ListServiceLevelObjectivesQueryData) (*applicationsignals.ListServiceLevelObjectivesInput, error) {
+	input := &applicationsignals.ListServiceLevelObjectivesInput{
+		IncludeLinkedAccounts: queryData.IncludeLinkedAccounts,
+	}
+
+	if queryData.ServiceString != "" {
+		serviceMap := map[string]string{}
+		err := json.Unmarshal([]byte(queryData.ServiceString), &serviceMap)
+		if err != nil {
+			return nil, backend.PluginError(err)
+		}
+		input.KeyAttributes = serviceMap
+	}
+	if queryData.OperationName != "" {
+		input.OperationName = aws.String(queryData.OperationName)
+	}
+
+	if input.IncludeLinkedAccounts {
+		// only replace the value if accountId is set on the query
+		if queryData.AccountId != "" && queryData.AccountId != "all" {
+			if input.KeyAttributes != nil {
+				input.KeyAttributes["AwsAccountId"] = queryData.AccountId
+			} else {
+				// Without a service the account can only be selected as the owner of the SLOs
+				input.SloOwnerAwsAccountId = aws.String(queryData.AccountId)
+			}
+		}
+	} else {
+		if input.KeyAttributes["AwsAccountId"] != "" {
+			// only include accountId attribute of the service if IncludeLinkedAccounts is true
+			delete(input.KeyAttributes, "AwsAccountId")
+		}
+	}
+
+	for _, metricSourceType := range queryData.MetricSourceTypes {
+		input.MetricSourceTypes = append(input.MetricSourceTypes, appSignalsTypes.MetricSourceType(metricSourceType))
+	}
+
+	if queryData.DependencyString != "" {
+		dependencyMap := map[string]string{}
+		err := json.Unmarshal([]byte(queryData.DependencyString), &dependencyMap)
+		if err != nil {
+			return nil, backend.PluginError(err)
+		}
+		input.DependencyConfig = &appSignalsTypes.DependencyConfig{DependencyKeyAttributes: dependencyMap}
+		if queryData.DependencyOperationName != "" {
+			input.DependencyConfig.DependencyOperationName = aws.String(queryData.DependencyOperationName)
+		}
+	}
+
+	return input, nil
+}
+
+// keyAttributesString returns the key attributes as sorted key:value pairs, or nil if there are none.
+func keyAttributesString(keyAttributes map[string]string) *string {
+	// sort the keys to ensure consistent ordering and testability
+	sortedAttributeKeys := make([]string, 0, len(keyAttributes))
+	for k := range keyAttributes {
+		sortedAttributeKeys = append(sortedAttributeKeys, k)
+	}
+	sort.Strings(sortedAttributeKeys)
+
+	// build key:value strings for sorted attributes
+	attributePairs := make([]string, 0, len(keyAttributes))
+	for _, k := range sortedAttributeKeys {
+		attributePairs = append(attributePairs, fmt.Sprintf("%s:%s", k, keyAttributes[k]))
+	}
+
+	if len(attributePairs) == 0 {
+		return nil
+	}
+	return aws.String(strings.Join(attributePairs, ", "))
+}
@@ -13,6 +13,8 @@ import (
 type ListServiceOperationsQueryData struct {
 	Region        string `json:"region,omitempty"`
 	ServiceString string `json:"serviceString,omitempty"`
+	// AttributesFormat is AttributesFormatString or AttributesFormatJSON.
+	AttributesFormat string `json:"attributesFormat,omitempty"`
 }
 
 func (ds *Datasource) ListServiceOperations(ctx context.Context, query backend.DataQuery, pluginContext backend.PluginContext) backend.DataResponse {
@@ -50,7 +52,7 @@ func (ds *Datasource) ListServiceOperations(ctx context.Context, query backend.D
 		data.NewField("MetricType", nil, []*string{}),
 		data.NewField("Namespace", nil, []*string{}),
 		data.NewField("AccountId", nil, []*string{}),
-		data.NewField("Dimensions", nil, []*string{}),
+		newAttributesField("Dimensions", queryData.AttributesFormat),
 	)
 
 	pager := applicationsignals.NewListServiceOperationsPaginator(appSignalsClient, &input)
@@ -65,24 +67,14 @@ func (ds *Datasource) ListServiceOperations(ctx context.Context, query backend.D
 
 		for _, operation := range output.ServiceOperations {
 			for _, metric := range operation.MetricReferences {
-				dimensions := ""
-				for i, dimension := range metric.Dimensions {
-					if i > 0 {
-						dimensions += " "
-					}
-					dimensions += *dimension.Name + "=\"" + *dimension.Value + "\""
-				}
-				var dimensionsPtr *string
-				if len(dimensions) > 0 {
-					dimensionsPtr = &dimensions
-				}
+				dimensions := attributesValue(dimensionsMap(metric.Dimensions), queryData.AttributesFormat, dimensionsString(metric.Dimensions))
 				listServicesFrame.AppendRow(
 					operation.Name,
 					metric.MetricName,
 					metric.MetricType,
 					metric.Namespace,
 					metric.AccountId,
-					dimensionsPtr,
+					dimensions,
 				)
 			}
 		}
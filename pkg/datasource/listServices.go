@@ -5,17 +5,71 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/aws/aws-sdk-go-v2/service/applicationsignals"
+	appSignalsTypes "github.com/aws/aws-sdk-go-v2/service/applicationsignals/types"
+)
+
+// Formats of the key attributes and dimensions in the Application Signals frames. The string format is different in
+// each frame and is the default to keep the queries saved before the JSON format working.
+const (
+	AttributesFormatString = "string"
+	AttributesFormatJSON   = "json"
 )
 
 type ListServicesQueryData struct {
 	Region                string `json:"region,omitempty"`
 	AccountId             string `json:"accountId,omitempty"`
 	IncludeLinkedAccounts bool   `json:"includeLinkedAccounts,omitempty"`
+	AttributesFormat      string `json:"attributesFormat,omitempty"`
+}
+
+// newAttributesField returns a field for key attributes or dimensions, which are JSON objects in the JSON format.
+func newAttributesField(name string, format string) *data.Field {
+	if format == AttributesFormatJSON {
+		return data.NewField(name, nil, []*json.RawMessage{})
+	}
+	return data.NewField(name, nil, []*string{})
+}
+
+// attributesValue returns the value of a newAttributesField field, legacy is the value in the string format.
+func attributesValue(attributes map[string]string, format string, legacy *string) any {
+	if format != AttributesFormatJSON {
+		return legacy
+	}
+	if len(attributes) == 0 {
+		return (*json.RawMessage)(nil)
+	}
+	// Keys of maps are sorted by json.Marshal and a map of strings always marshals
+	attributesBytes, _ := json.Marshal(attributes)
+	value := json.RawMessage(attributesBytes)
+	return &value
+}
+
+// dimensionsMap returns the dimensions of a metric by their name.
+func dimensionsMap(dimensions []appSignalsTypes.Dimension) map[string]string {
+	result := make(map[string]string, len(dimensions))
+	for _, dimension := range dimensions {
+		result[Dereference(dimension.Name)] = Dereference(dimension.Value)
+	}
+	return result
+}
+
+// dimensionsString returns the dimensions as space separated name="value" pairs, or nil if there are none.
+func dimensionsString(dimensions []appSignalsTypes.Dimension) *string {
+	pairs := make([]string, 0, len(dimensions))
+	for _, dimension := range dimensions {
+		pairs = append(pairs, Dereference(dimension.Name)+"=\""+Dereference(dimension.Value)+"\"")
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+	result := strings.Join(pairs, " ")
+	return &result
 }
 
 func buildKeyAttributes(keyAttributes map[string]string) (string, error) {
@@ -81,8 +135,13 @@ func (ds *Datasource) ListServices(ctx context.Context, query backend.DataQuery,
 		data.NewField("Telemetry.SDK", nil, []string{}),
 		data.NewField("Telemetry.Agent", nil, []string{}),
 		data.NewField("Telemetry.Source", nil, []string{}),
-		data.NewField("KeyAttributes", nil, []string{}),
 	)
+	if queryData.AttributesFormat == AttributesFormatJSON {
+		listServicesFrame.Fields = append(listServicesFrame.Fields, newAttributesField("KeyAttributes", queryData.AttributesFormat))
+	} else {
+		// The key attributes were always a JSON string in this frame
+		listServicesFrame.Fields = append(listServicesFrame.Fields, data.NewField("KeyAttributes", nil, []string{}))
+	}
 
 	pager := applicationsignals.NewListServicesPaginator(appSignalsClient, &input)
 	var pagerError error
@@ -123,9 +182,14 @@ func (ds *Datasource) ListServices(ctx context.Context, query backend.DataQuery,
 					telemetrySource = currentMap["Telemetry.Source"]
 				}
 			}
-			keyAttributes, err := buildKeyAttributes(summary.KeyAttributes)
-			if err != nil {
-				return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+			var keyAttributes any
+			if queryData.AttributesFormat == AttributesFormatJSON {
+				keyAttributes = attributesValue(summary.KeyAttributes, queryData.AttributesFormat, nil)
+			} else {
+				keyAttributes, err = buildKeyAttributes(summary.KeyAttributes)
+				if err != nil {
+					return backend.ErrorResponseWithErrorSource(backend.DownstreamError(err))
+				}
 			}
 
 			listServicesFrame.AppendRow(
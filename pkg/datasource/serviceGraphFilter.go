@@ -0,0 +1,201 @@
+package datasource
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+)
+
+const (
+	// Type X-Ray uses for the nodes representing clients calling the root services.
+	clientNodeType = "client"
+
+	FocusDirectionUpstream   = "upstream"
+	FocusDirectionDownstream = "downstream"
+)
+
+// filterServiceGraph applies the filters from the query to the services returned by GetServiceGraph. Edges pointing to
+// services that were filtered out are kept and are skipped when creating the frames.
+func filterServiceGraph(services []xraytypes.Service, queryData *GetServiceMapQueryData) []xraytypes.Service {
+	if queryData.CollapseClients {
+		services = collapseClientNodes(services)
+	}
+	// Neighbourhood is computed before excluding types so the hops are counted on the whole graph.
+	if queryData.FocusService != "" {
+		services = serviceNeighbourhood(services, queryData.FocusService, queryData.FocusDepth, queryData.FocusDirection)
+	}
+	if len(queryData.ExcludeTypes) > 0 {
+		services = slices.DeleteFunc(services, func(service xraytypes.Service) bool {
+			return slices.Contains(queryData.ExcludeTypes, Dereference(service.Type))
+		})
+	}
+	return services
+}
+
+// collapseClientNodes merges all the client nodes into the first one. X-Ray creates a separate client node for each
+// root service which makes big maps hard to read. Client nodes do not have incoming edges so only their outgoing edges
+// need to be merged.
+func collapseClientNodes(services []xraytypes.Service) []xraytypes.Service {
+	var collapsed []xraytypes.Service
+	clientIndex := -1
+	for _, service := range services {
+		if Dereference(service.Type) != clientNodeType {
+			collapsed = append(collapsed, service)
+			continue
+		}
+		if clientIndex == -1 {
+			clientIndex = len(collapsed)
+			// Copy the edges so we do not modify the original service when merging.
+			service.Edges = slices.Clone(service.Edges)
+			collapsed = append(collapsed, service)
+			continue
+		}
+		collapsed[clientIndex].Edges = mergeEdges(collapsed[clientIndex].Edges, service.Edges)
+	}
+	return collapsed
+}
+
+// mergeEdges adds edges to the existing ones, combining the statistics of edges going to the same service.
+func mergeEdges(edges []xraytypes.Edge, newEdges []xraytypes.Edge) []xraytypes.Edge {
+	for _, newEdge := range newEdges {
+		index := slices.IndexFunc(edges, func(edge xraytypes.Edge) bool {
+			return Dereference(edge.ReferenceId) == Dereference(newEdge.ReferenceId)
+		})
+		if index == -1 {
+			edges = append(edges, newEdge)
+			continue
+		}
+		edge := edges[index]
+		edge.StartTime = minTime(edge.StartTime, newEdge.StartTime)
+		edge.EndTime = maxTime(edge.EndTime, newEdge.EndTime)
+		edge.SummaryStatistics = addEdgeStatistics(edge.SummaryStatistics, newEdge.SummaryStatistics)
+		edge.ResponseTimeHistogram = append(slices.Clone(edge.ResponseTimeHistogram), newEdge.ResponseTimeHistogram...)
+		edges[index] = edge
+	}
+	return edges
+}
+
+func addEdgeStatistics(a *xraytypes.EdgeStatistics, b *xraytypes.EdgeStatistics) *xraytypes.EdgeStatistics {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	sum := &xraytypes.EdgeStatistics{
+		OkCount:           addInt64(a.OkCount, b.OkCount),
+		TotalCount:        addInt64(a.TotalCount, b.TotalCount),
+		TotalResponseTime: aws.Float64(Dereference(a.TotalResponseTime) + Dereference(b.TotalResponseTime)),
+	}
+	if a.ErrorStatistics != nil || b.ErrorStatistics != nil {
+		errorA, errorB := Dereference(a.ErrorStatistics), Dereference(b.ErrorStatistics)
+		sum.ErrorStatistics = &xraytypes.ErrorStatistics{
+			OtherCount:    addInt64(errorA.OtherCount, errorB.OtherCount),
+			ThrottleCount: addInt64(errorA.ThrottleCount, errorB.ThrottleCount),
+			TotalCount:    addInt64(errorA.TotalCount, errorB.TotalCount),
+		}
+	}
+	if a.FaultStatistics != nil || b.FaultStatistics != nil {
+		faultA, faultB := Dereference(a.FaultStatistics), Dereference(b.FaultStatistics)
+		sum.FaultStatistics = &xraytypes.FaultStatistics{
+			OtherCount: addInt64(faultA.OtherCount, faultB.OtherCount),
+			TotalCount: addInt64(faultA.TotalCount, faultB.TotalCount),
+		}
+	}
+	return sum
+}
+
+func addInt64(a *int64, b *int64) *int64 {
+	if a == nil && b == nil {
+		return nil
+	}
+	return aws.Int64(Dereference(a) + Dereference(b))
+}
+
+func minTime(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
+
+// validateFocusDirection returns an error if direction is not FocusDirectionUpstream, FocusDirectionDownstream or empty
+// for both directions.
+func validateFocusDirection(direction string) error {
+	if direction != "" && direction != FocusDirectionUpstream && direction != FocusDirectionDownstream {
+		return fmt.Errorf("invalid focus direction %q, expected %q or %q", direction, FocusDirectionUpstream, FocusDirectionDownstream)
+	}
+	return nil
+}
+
+// serviceNeighbourhood returns the services named name and all the services reachable from them in up to depth hops.
+// Downstream follows the edges from callers to the called services, upstream goes the other way. Empty direction
+// includes both.
+func serviceNeighbourhood(services []xraytypes.Service, name string, depth int, direction string) []xraytypes.Service {
+	downstream := make(map[int32][]int32)
+	upstream := make(map[int32][]int32)
+	var focused []int32
+	for _, service := range services {
+		id := Dereference(service.ReferenceId)
+		if Dereference(service.Name) == name || slices.Contains(service.Names, name) {
+			focused = append(focused, id)
+		}
+		for _, edge := range service.Edges {
+			target := Dereference(edge.ReferenceId)
+			downstream[id] = append(downstream[id], target)
+			upstream[target] = append(upstream[target], id)
+		}
+	}
+
+	included := make(map[int32]bool)
+	if direction != FocusDirectionUpstream {
+		walkServiceGraph(focused, downstream, depth, included)
+	}
+	if direction != FocusDirectionDownstream {
+		walkServiceGraph(focused, upstream, depth, included)
+	}
+
+	var neighbourhood []xraytypes.Service
+	for _, service := range services {
+		if included[Dereference(service.ReferenceId)] {
+			neighbourhood = append(neighbourhood, service)
+		}
+	}
+	return neighbourhood
+}
+
+// walkServiceGraph does a breadth first search from the start services and marks every service it reaches within
+// depth hops as visited.
+func walkServiceGraph(start []int32, adjacency map[int32][]int32, depth int, visited map[int32]bool) {
+	hops := make(map[int32]int)
+	queue := slices.Clone(start)
+	for _, id := range start {
+		hops[id] = 0
+		visited[id] = true
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if depth > 0 && hops[id] >= depth {
+			continue
+		}
+		for _, next := range adjacency[id] {
+			if _, seen := hops[next]; seen {
+				continue
+			}
+			hops[next] = hops[id] + 1
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+}
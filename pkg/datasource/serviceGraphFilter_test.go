@@ -0,0 +1,90 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/stretchr/testify/require"
+)
+
+// makeServiceGraph returns graph:
+//
+//	client(0) -> A(2) -> B(3) -> table(4)
+//	client(1) -> C(5) -> B(3)
+func makeServiceGraph() []xraytypes.Service {
+	edge := func(id int32, total int64) xraytypes.Edge {
+		return xraytypes.Edge{
+			ReferenceId:       aws.Int32(id),
+			SummaryStatistics: &xraytypes.EdgeStatistics{OkCount: aws.Int64(total), TotalCount: aws.Int64(total), TotalResponseTime: aws.Float64(1)},
+		}
+	}
+	service := func(id int32, name string, serviceType string, edges ...xraytypes.Edge) xraytypes.Service {
+		return xraytypes.Service{ReferenceId: aws.Int32(id), Name: aws.String(name), Type: aws.String(serviceType), Edges: edges}
+	}
+	return []xraytypes.Service{
+		service(0, "client", clientNodeType, edge(2, 10)),
+		service(1, "client", clientNodeType, edge(5, 5), edge(2, 20)),
+		service(2, "A", "AWS::Lambda", edge(3, 10)),
+		service(3, "B", "AWS::Lambda::Function", edge(4, 10)),
+		service(4, "table", "AWS::DynamoDB::Table"),
+		service(5, "C", "AWS::EC2::Instance", edge(3, 5)),
+	}
+}
+
+func serviceNames(services []xraytypes.Service) []string {
+	var names []string
+	for _, service := range services {
+		names = append(names, *service.Name)
+	}
+	return names
+}
+
+func TestFilterServiceGraph(t *testing.T) {
+	t.Run("returns all services without filters", func(t *testing.T) {
+		services := filterServiceGraph(makeServiceGraph(), &GetServiceMapQueryData{})
+		require.Equal(t, []string{"client", "client", "A", "B", "table", "C"}, serviceNames(services))
+	})
+
+	t.Run("focuses on a service in both directions", func(t *testing.T) {
+		services := filterServiceGraph(makeServiceGraph(), &GetServiceMapQueryData{FocusService: "A", FocusDepth: 1})
+		require.Equal(t, []string{"client", "client", "A", "B"}, serviceNames(services))
+	})
+
+	t.Run("focuses on a service downstream without depth limit", func(t *testing.T) {
+		services := filterServiceGraph(makeServiceGraph(), &GetServiceMapQueryData{FocusService: "C", FocusDirection: FocusDirectionDownstream})
+		require.Equal(t, []string{"B", "table", "C"}, serviceNames(services))
+	})
+
+	t.Run("focuses on a service upstream", func(t *testing.T) {
+		services := filterServiceGraph(makeServiceGraph(), &GetServiceMapQueryData{FocusService: "B", FocusDepth: 1, FocusDirection: FocusDirectionUpstream})
+		require.Equal(t, []string{"A", "B", "C"}, serviceNames(services))
+	})
+
+	t.Run("returns no services when focused service is not found", func(t *testing.T) {
+		services := filterServiceGraph(makeServiceGraph(), &GetServiceMapQueryData{FocusService: "unknown"})
+		require.Empty(t, services)
+	})
+
+	t.Run("excludes service types", func(t *testing.T) {
+		services := filterServiceGraph(makeServiceGraph(), &GetServiceMapQueryData{ExcludeTypes: []string{"AWS::DynamoDB::Table", clientNodeType}})
+		require.Equal(t, []string{"A", "B", "C"}, serviceNames(services))
+	})
+
+	t.Run("collapses client nodes and merges their edges", func(t *testing.T) {
+		original := makeServiceGraph()
+		services := filterServiceGraph(original, &GetServiceMapQueryData{CollapseClients: true})
+		require.Equal(t, []string{"client", "A", "B", "table", "C"}, serviceNames(services))
+
+		client := services[0]
+		require.Equal(t, 2, len(client.Edges))
+		require.Equal(t, int32(2), *client.Edges[0].ReferenceId)
+		require.Equal(t, int64(30), *client.Edges[0].SummaryStatistics.TotalCount)
+		require.Equal(t, 2.0, *client.Edges[0].SummaryStatistics.TotalResponseTime)
+		require.Equal(t, int32(5), *client.Edges[1].ReferenceId)
+
+		// the services from the API response are not modified
+		require.Equal(t, 1, len(original[0].Edges))
+		require.Equal(t, int64(10), *original[0].Edges[0].SummaryStatistics.TotalCount)
+	})
+}
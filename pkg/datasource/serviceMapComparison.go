@@ -0,0 +1,139 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	ServiceMapStatusAdded     = "added"
+	ServiceMapStatusRemoved   = "removed"
+	ServiceMapStatusChanged   = "changed"
+	ServiceMapStatusUnchanged = "unchanged"
+
+	// Relative change of the average response time for a node or edge to be marked as changed.
+	responseTimeChangeThreshold = 0.1
+	// Absolute change of the fault rate for a node or edge to be marked as changed.
+	faultRateChangeThreshold = 0.01
+)
+
+// getServiceMapComparison gets the service graph for the query time range and for the same time range shifted back by
+// CompareTimeShift and returns nodes and edges of both, annotated with their status and change of response time and
+// fault rate.
+func getServiceMapComparison(ctx context.Context, xrayClient XrayClient, query backend.DataQuery, queryData *GetServiceMapQueryData) backend.DataResponse {
+	shift, err := gtime.ParseDuration(queryData.CompareTimeShift)
+	if err != nil {
+		return backend.ErrorResponseWithErrorSource(backend.DownstreamError(fmt.Errorf("invalid compare time shift %q: %w", queryData.CompareTimeShift, err)))
+	}
+
+	var before, after []xraytypes.Service
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.Go(func() error {
+		services, err := getServiceGraph(groupCtx, xrayClient, query.TimeRange.From.Add(-shift), query.TimeRange.To.Add(-shift), queryData)
+		before = services
+		return err
+	})
+	group.Go(func() error {
+		services, err := getServiceGraph(groupCtx, xrayClient, query.TimeRange.From, query.TimeRange.To, queryData)
+		after = services
+		return err
+	})
+	if err := group.Wait(); err != nil {
+		return backend.ErrorResponseWithErrorSource(err)
+	}
+
+	return backend.DataResponse{
+		Frames: serviceMapComparisonFrames(filterServiceGraph(before, queryData), filterServiceGraph(after, queryData)),
+	}
+}
+
+// serviceMapComparisonFrames returns node graph frames with all the nodes and edges from both service graphs. Nodes are
+// matched by name, type and account as ReferenceId is not stable between the API calls. Client nodes are always
+// collapsed as they could not be told apart otherwise.
+func serviceMapComparisonFrames(before []xraytypes.Service, after []xraytypes.Service) []*data.Frame {
+	beforeNodes, beforeEdges := serviceGraphNodes(collapseClientNodes(before), serviceKey)
+	afterNodes, afterEdges := serviceGraphNodes(collapseClientNodes(after), serviceKey)
+
+	nodesFrame := newServiceMapNodesFrame()
+	addComparisonFields(nodesFrame)
+	beforeNodesById := make(map[string]serviceMapNode)
+	for _, node := range beforeNodes {
+		beforeNodesById[node.id] = node
+	}
+	afterNodesById := make(map[string]bool)
+	for _, node := range afterNodes {
+		afterNodesById[node.id] = true
+		if beforeNode, ok := beforeNodesById[node.id]; ok {
+			nodesFrame.AppendRow(append(node.values(), compareStatistics(beforeNode.stats, node.stats)...)...)
+		} else {
+			nodesFrame.AppendRow(append(node.values(), ServiceMapStatusAdded, nil, nil)...)
+		}
+	}
+	for _, node := range beforeNodes {
+		if !afterNodesById[node.id] {
+			nodesFrame.AppendRow(append(node.values(), ServiceMapStatusRemoved, nil, nil)...)
+		}
+	}
+
+	edgesFrame := newServiceMapEdgesFrame()
+	addComparisonFields(edgesFrame)
+	beforeEdgesById := make(map[string]serviceMapEdge)
+	for _, edge := range beforeEdges {
+		beforeEdgesById[edge.id] = edge
+	}
+	afterEdgesById := make(map[string]bool)
+	for _, edge := range afterEdges {
+		afterEdgesById[edge.id] = true
+		if beforeEdge, ok := beforeEdgesById[edge.id]; ok {
+			edgesFrame.AppendRow(append(edge.values(), compareStatistics(beforeEdge.stats, edge.stats)...)...)
+		} else {
+			edgesFrame.AppendRow(append(edge.values(), ServiceMapStatusAdded, nil, nil)...)
+		}
+	}
+	for _, edge := range beforeEdges {
+		if !afterEdgesById[edge.id] {
+			edgesFrame.AppendRow(append(edge.values(), ServiceMapStatusRemoved, nil, nil)...)
+		}
+	}
+
+	return []*data.Frame{nodesFrame, edgesFrame}
+}
+
+// addComparisonFields adds fields for the values returned by compareStatistics. They are shown as details in the node
+// graph context menu.
+func addComparisonFields(frame *data.Frame) {
+	frame.Fields = append(frame.Fields,
+		data.NewField("detail__status", nil, []string{}).SetConfig(&data.FieldConfig{DisplayName: "Status"}),
+		data.NewField("detail__responseTimeDelta", nil, []*float64{}).SetConfig(&data.FieldConfig{DisplayName: "Response time change", Unit: "ms"}),
+		data.NewField("detail__faultRateDelta", nil, []*float64{}).SetConfig(&data.FieldConfig{DisplayName: "Fault rate change", Unit: "percentunit"}),
+	)
+}
+
+// compareStatistics returns the status and the change of average response time and fault rate between before and after.
+func compareStatistics(before summaryStatistics, after summaryStatistics) []interface{} {
+	responseTimeDelta := after.averageResponseTime() - before.averageResponseTime()
+	faultRateDelta := after.faultRate() - before.faultRate()
+
+	status := ServiceMapStatusUnchanged
+	responseTimeChanged := math.Abs(responseTimeDelta) > before.averageResponseTime()*responseTimeChangeThreshold
+	if responseTimeChanged || math.Abs(faultRateDelta) > faultRateChangeThreshold {
+		status = ServiceMapStatusChanged
+	}
+	return []interface{}{status, &responseTimeDelta, &faultRateDelta}
+}
+
+// serviceKey identifies the service across different service graph responses.
+func serviceKey(service xraytypes.Service) string {
+	key := fmt.Sprintf("%s (%s)", Dereference(service.Name), Dereference(service.Type))
+	if service.AccountId != nil {
+		key += " " + *service.AccountId
+	}
+	return key
+}
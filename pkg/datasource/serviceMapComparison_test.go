@@ -0,0 +1,80 @@
+package datasource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceMapComparisonFrames(t *testing.T) {
+	before := makeServiceGraph()
+	after := makeServiceGraph()
+	// ReferenceIds are different in each response so they should not be used to match the services.
+	for i := range after {
+		after[i].ReferenceId = aws.Int32(*after[i].ReferenceId + 10)
+		for j := range after[i].Edges {
+			after[i].Edges[j].ReferenceId = aws.Int32(*after[i].Edges[j].ReferenceId + 10)
+		}
+	}
+	// A -> B gets slower
+	after[2].Edges[0].SummaryStatistics = &xraytypes.EdgeStatistics{OkCount: aws.Int64(10), TotalCount: aws.Int64(10), TotalResponseTime: aws.Float64(2)}
+	// C and its edges are removed and D is added
+	after = append(after[:5], xraytypes.Service{ReferenceId: aws.Int32(20), Name: aws.String("D"), Type: aws.String("AWS::EC2::Instance")})
+	after[1].Edges = []xraytypes.Edge{{ReferenceId: aws.Int32(20)}, after[1].Edges[1]}
+
+	frames := serviceMapComparisonFrames(before, after)
+	nodes, edges := frames[0], frames[1]
+
+	nodeStatus := map[string]interface{}{}
+	for i := 0; i < nodes.Rows(); i++ {
+		nodeStatus[nodes.Fields[0].At(i).(string)] = nodes.Fields[9].At(i)
+	}
+	require.Equal(t, map[string]interface{}{
+		// client statistics are the sum of its edges so they change when the edge to C is removed
+		"client (client)": ServiceMapStatusChanged,
+		// A has no statistics of its own so they are computed from its edge to B
+		"A (AWS::Lambda)":              ServiceMapStatusChanged,
+		"B (AWS::Lambda::Function)":    ServiceMapStatusUnchanged,
+		"table (AWS::DynamoDB::Table)": ServiceMapStatusUnchanged,
+		"D (AWS::EC2::Instance)":       ServiceMapStatusAdded,
+		"C (AWS::EC2::Instance)":       ServiceMapStatusRemoved,
+	}, nodeStatus)
+
+	edgeStatus := map[string]interface{}{}
+	for i := 0; i < edges.Rows(); i++ {
+		edgeStatus[edges.Fields[0].At(i).(string)] = edges.Fields[7].At(i)
+		if edges.Fields[0].At(i) == "A (AWS::Lambda)__B (AWS::Lambda::Function)" {
+			require.Equal(t, float64(100), *edges.Fields[8].At(i).(*float64))
+			require.Equal(t, float64(0), *edges.Fields[9].At(i).(*float64))
+		}
+	}
+	require.Equal(t, map[string]interface{}{
+		"client (client)__A (AWS::Lambda)":                        ServiceMapStatusUnchanged,
+		"client (client)__D (AWS::EC2::Instance)":                 ServiceMapStatusAdded,
+		"client (client)__C (AWS::EC2::Instance)":                 ServiceMapStatusRemoved,
+		"A (AWS::Lambda)__B (AWS::Lambda::Function)":              ServiceMapStatusChanged,
+		"B (AWS::Lambda::Function)__table (AWS::DynamoDB::Table)": ServiceMapStatusUnchanged,
+		"C (AWS::EC2::Instance)__B (AWS::Lambda::Function)":       ServiceMapStatusRemoved,
+	}, edgeStatus)
+}
+
+func TestServiceGraphNodesClientStatistics(t *testing.T) {
+	services := makeServiceGraph()
+	services[1].Edges[0].StartTime = aws.Time(time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC))
+	services[1].Edges[0].EndTime = aws.Time(time.Date(2020, 6, 20, 1, 5, 0, 0, time.UTC))
+	services[1].Edges[1].StartTime = aws.Time(time.Date(2020, 6, 20, 1, 5, 0, 0, time.UTC))
+	services[1].Edges[1].EndTime = aws.Time(time.Date(2020, 6, 20, 1, 10, 0, 0, time.UTC))
+
+	nodes, _ := serviceGraphNodes(services, serviceNodeId)
+
+	// client(1) has no statistics of its own so they are the sum of both of its edges
+	client := nodes[1]
+	require.Equal(t, int64(25), client.stats.totalCount)
+	require.Equal(t, float64(2), client.stats.totalResponseTime)
+	require.Equal(t, time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC), *client.startTime)
+	require.Equal(t, time.Date(2020, 6, 20, 1, 10, 0, 0, time.UTC), *client.endTime)
+	require.Equal(t, 2.5, *client.stats.requestsPerMinute(client.startTime, client.endTime))
+}
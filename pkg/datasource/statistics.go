@@ -0,0 +1,137 @@
+package datasource
+
+import (
+	"slices"
+	"time"
+
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+)
+
+// summaryStatistics holds the values shared by xraytypes.EdgeStatistics and xraytypes.ServiceStatistics. They have the
+// same structure but are different types, so we copy them here to be able to compute derived values in one place.
+type summaryStatistics struct {
+	okCount           int64
+	errorCount        int64
+	throttleCount     int64
+	faultCount        int64
+	totalCount        int64
+	totalResponseTime float64
+}
+
+func fromServiceStatistics(stats *xraytypes.ServiceStatistics) summaryStatistics {
+	if stats == nil {
+		return summaryStatistics{}
+	}
+	return newSummaryStatistics(stats.OkCount, stats.ErrorStatistics, stats.FaultStatistics, stats.TotalCount, stats.TotalResponseTime)
+}
+
+func fromEdgeStatistics(stats *xraytypes.EdgeStatistics) summaryStatistics {
+	if stats == nil {
+		return summaryStatistics{}
+	}
+	return newSummaryStatistics(stats.OkCount, stats.ErrorStatistics, stats.FaultStatistics, stats.TotalCount, stats.TotalResponseTime)
+}
+
+func newSummaryStatistics(okCount *int64, errorStats *xraytypes.ErrorStatistics, faultStats *xraytypes.FaultStatistics, totalCount *int64, totalResponseTime *float64) summaryStatistics {
+	stats := summaryStatistics{
+		okCount:           Dereference(okCount),
+		totalCount:        Dereference(totalCount),
+		totalResponseTime: Dereference(totalResponseTime),
+	}
+	if errorStats != nil {
+		stats.errorCount = Dereference(errorStats.TotalCount)
+		stats.throttleCount = Dereference(errorStats.ThrottleCount)
+	}
+	if faultStats != nil {
+		stats.faultCount = Dereference(faultStats.TotalCount)
+	}
+	return stats
+}
+
+func (stats summaryStatistics) add(other summaryStatistics) summaryStatistics {
+	return summaryStatistics{
+		okCount:           stats.okCount + other.okCount,
+		errorCount:        stats.errorCount + other.errorCount,
+		throttleCount:     stats.throttleCount + other.throttleCount,
+		faultCount:        stats.faultCount + other.faultCount,
+		totalCount:        stats.totalCount + other.totalCount,
+		totalResponseTime: stats.totalResponseTime + other.totalResponseTime,
+	}
+}
+
+// averageResponseTime returns the average response time in milliseconds.
+func (stats summaryStatistics) averageResponseTime() float64 {
+	if stats.totalCount == 0 {
+		return 0
+	}
+	return stats.totalResponseTime / float64(stats.totalCount) * 1000
+}
+
+// rate returns count as a ratio of all the requests.
+func (stats summaryStatistics) rate(count int64) float64 {
+	if stats.totalCount == 0 {
+		return 0
+	}
+	return float64(count) / float64(stats.totalCount)
+}
+
+func (stats summaryStatistics) successRate() float64 {
+	return stats.rate(stats.okCount)
+}
+
+// errorRate does not include throttled requests even though X-Ray counts them as errors, so they can be shown
+// separately.
+func (stats summaryStatistics) errorRate() float64 {
+	return stats.rate(stats.errorCount - stats.throttleCount)
+}
+
+func (stats summaryStatistics) throttleRate() float64 {
+	return stats.rate(stats.throttleCount)
+}
+
+func (stats summaryStatistics) faultRate() float64 {
+	return stats.rate(stats.faultCount)
+}
+
+// requestsPerMinute returns nil if there is nothing to compute the rate from.
+func (stats summaryStatistics) requestsPerMinute(startTime *time.Time, endTime *time.Time) *float64 {
+	if stats.totalCount == 0 || startTime == nil || endTime == nil || !endTime.After(*startTime) {
+		return nil
+	}
+	perMinute := float64(stats.totalCount) / endTime.Sub(*startTime).Minutes()
+	return &perMinute
+}
+
+// histogramPercentile returns the smallest value from the histogram that is greater or equal to the percentile of
+// all the counted values, or nil for an empty histogram.
+func histogramPercentile(histogram []xraytypes.HistogramEntry, percentile float64) *float64 {
+	sorted := slices.Clone(histogram)
+	slices.SortFunc(sorted, func(a, b xraytypes.HistogramEntry) int {
+		if a.Value < b.Value {
+			return -1
+		}
+		if a.Value > b.Value {
+			return 1
+		}
+		return 0
+	})
+
+	var total int64
+	for _, entry := range sorted {
+		total += int64(entry.Count)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	threshold := float64(total) * percentile / 100
+	var cumulative int64
+	for _, entry := range sorted {
+		cumulative += int64(entry.Count)
+		if float64(cumulative) >= threshold {
+			value := entry.Value
+			return &value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package datasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/xray"
+	xraytypes "github.com/aws/aws-sdk-go-v2/service/xray/types"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramPercentile(t *testing.T) {
+	histogram := []xraytypes.HistogramEntry{
+		{Value: 0.3, Count: 1},
+		{Value: 0.1, Count: 50},
+		{Value: 0.2, Count: 49},
+	}
+	require.Equal(t, 0.1, *histogramPercentile(histogram, 50))
+	require.Equal(t, 0.2, *histogramPercentile(histogram, 90))
+	require.Equal(t, 0.2, *histogramPercentile(histogram, 99))
+	require.Equal(t, 0.3, *histogramPercentile(histogram, 100))
+	require.Nil(t, histogramPercentile(nil, 50))
+}
+
+func TestAddToBucket(t *testing.T) {
+	timestamp := time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)
+	buckets := make(map[time.Time]*statisticsBucket)
+	addToBucket(buckets, timestamp, summaryStatistics{okCount: 1, totalCount: 2, totalResponseTime: 1}, []xraytypes.HistogramEntry{{Value: 0.1, Count: 2}}, nil)
+	addToBucket(buckets, timestamp, summaryStatistics{faultCount: 1, totalCount: 1, totalResponseTime: 2}, []xraytypes.HistogramEntry{{Value: 0.1, Count: 1}}, &xraytypes.ForecastStatistics{FaultCountHigh: aws.Int64(5), FaultCountLow: aws.Int64(1)})
+
+	require.Equal(t, 1, len(buckets))
+	require.Equal(t, summaryStatistics{okCount: 1, faultCount: 1, totalCount: 3, totalResponseTime: 3}, buckets[timestamp].stats)
+	require.Equal(t, []xraytypes.HistogramEntry{{Value: 0.1, Count: 3}}, buckets[timestamp].histogram)
+	require.Equal(t, int64(5), *buckets[timestamp].faultCountHigh)
+	require.Equal(t, int64(1), *buckets[timestamp].faultCountLow)
+}
+
+// statisticsXrayClient returns a single row with both edge and service statistics and keeps the last request.
+type statisticsXrayClient struct {
+	XrayClient
+	input *xray.GetTimeSeriesServiceStatisticsInput
+}
+
+func (client *statisticsXrayClient) GetTimeSeriesServiceStatistics(_ context.Context, input *xray.GetTimeSeriesServiceStatisticsInput, _ ...func(*xray.Options)) (*xray.GetTimeSeriesServiceStatisticsOutput, error) {
+	client.input = input
+	return &xray.GetTimeSeriesServiceStatisticsOutput{
+		TimeSeriesServiceStatistics: []xraytypes.TimeSeriesServiceStatistics{
+			{
+				Timestamp:                aws.Time(time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)),
+				EdgeSummaryStatistics:    &xraytypes.EdgeStatistics{OkCount: aws.Int64(1), TotalCount: aws.Int64(1)},
+				ServiceSummaryStatistics: &xraytypes.ServiceStatistics{OkCount: aws.Int64(2), TotalCount: aws.Int64(2)},
+			},
+		},
+	}, nil
+}
+
+func TestGetStatisticsBuckets(t *testing.T) {
+	client := &statisticsXrayClient{}
+	from := time.Date(2020, 6, 20, 1, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+	group := &xraytypes.Group{GroupName: aws.String("Checkout"), GroupARN: aws.String("CheckoutARN")}
+
+	buckets, err := getStatisticsBuckets(context.Background(), client, timeRange, 60, false, group, statisticsEntity{kind: GroupByService})
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	require.Equal(t, int64(2), buckets[0].stats.totalCount)
+	require.Equal(t, "Checkout", *client.input.GroupName)
+
+	buckets, err = getStatisticsBuckets(context.Background(), client, timeRange, 60, false, nil, statisticsEntity{kind: GroupByEdge})
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	require.Equal(t, int64(1), buckets[0].stats.totalCount)
+	require.Nil(t, client.input.GroupName)
+}
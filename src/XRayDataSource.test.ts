@@ -9,6 +9,8 @@ import {
   TypedVariableModel,
 } from '@grafana/data';
 import {
+  QueryMode,
+  ServicesQueryType,
   XrayJsonData,
   XrayQuery,
   XrayQueryType,
@@ -129,6 +131,23 @@ describe('XrayDataSource', () => {
       expect(df.fields[1].display?.(df.fields[1].values.get(2)).text).toBe('42 seconds');
     });
 
+    it('adds links to the log groups of a service', async () => {
+      const ds = makeDatasourceWithResponse(makeLogGroupsResponse());
+      const response = await firstValueFrom(
+        ds.query(
+          makeQuery({
+            queryMode: QueryMode.services,
+            serviceQueryType: ServicesQueryType.getServiceDetails,
+            region: 'eu-west-1',
+          })
+        )
+      );
+      const df: DataFrame = response.data[0];
+      expect(df.fields[0].config.links?.[0].url).toBe(
+        'https://eu-west-1.console.aws.amazon.com/cloudwatch/home?region=eu-west-1#logsV2:log-groups/log-group/${__value.raw:percentencode}'
+      );
+    });
+
     it('adds correct resolution based on interval', async () => {
       const ds = makeDatasourceWithResponse({} as any);
       await firstValueFrom(
@@ -178,6 +197,7 @@ describe('XrayDataSource', () => {
       );
       const mockQuery = (ds as any).mockQuery as jest.Mock;
       expect(mockQuery.mock.calls[0][0].targets[0].query).toBe('service("from group") AND service("something")');
+      expect(mockQuery.mock.calls[0][0].targets[0].groupFilterApplied).toBe(true);
     });
   });
   describe('.getXrayUrlForQuery', () => {
@@ -535,6 +555,21 @@ function makeInsightResponse(): DataFrame {
   };
 }
 
+function makeLogGroupsResponse(): DataFrame {
+  return {
+    name: 'ServiceLogGroups',
+    length: 1,
+    fields: [
+      {
+        name: 'LogGroupName',
+        type: FieldType.string,
+        values: ['/aws/application-signals/data'],
+        config: {},
+      },
+    ],
+  };
+}
+
 function makeServiceMapResponse(): DataFrame {
   return {
     name: 'ServiceMap',
@@ -18,7 +18,9 @@ import { map } from 'rxjs/operators';
 
 import {
   Group,
+  QueryMode,
   Region,
+  ServicesQueryType,
   XrayJsonData,
   XrayQuery,
   XrayQueryType,
@@ -41,6 +43,19 @@ export class XrayDataSource extends DataSourceWithBackend<XrayQuery, XrayJsonDat
     this.languageProvider = new XRayLanguageProvider(this);
     this.instanceSettings = instanceSettings;
     this.variables = new XrayVariableSupport(this);
+    this.annotations = {
+      // Insights table and SLO history can't be shown as annotations, so ask the backend for the annotation format.
+      prepareQuery: (annotation) => {
+        const target = annotation.target;
+        if (target?.queryType === XrayQueryType.getInsights) {
+          return { ...target, queryType: XrayQueryType.getInsightAnnotations };
+        }
+        if (target?.queryMode === QueryMode.services && target.serviceQueryType === ServicesQueryType.getSLOHistory) {
+          return { ...target, serviceQueryType: ServicesQueryType.getSLOBreaches };
+        }
+        return target;
+      },
+    };
   }
 
   query(request: DataQueryRequest<XrayQuery>): Observable<DataQueryResponse> {
@@ -215,6 +230,11 @@ export class XrayDataSource extends DataSourceWithBackend<XrayQuery, XrayJsonDat
     return `https://${region}.console.aws.amazon.com/xray/home?region=${region}`;
   }
 
+  private getCloudWatchUrl(region?: string): string {
+    region = !region || region === 'default' ? this.instanceSettings.jsonData.defaultRegion! : region;
+    return `https://${region}.console.aws.amazon.com/cloudwatch/home?region=${region}`;
+  }
+
   private parseResponse(response: DataFrame, query?: XrayQuery): DataFrame[] {
     // TODO this would better be based on type but backend Go def does not have dataFrame.type
     switch (response.name) {
@@ -224,8 +244,16 @@ export class XrayDataSource extends DataSourceWithBackend<XrayQuery, XrayJsonDat
         return parseTracesListResponse(response, this.instanceSettings, query);
       case 'InsightSummaries':
         return this.parseInsightsResponse(response, query?.region);
+      case 'ServiceLogGroups':
+        return this.parseLogGroupsResponse(response, query?.region);
       case 'ServiceMap':
         return parseServiceMapResponse(response, this.instanceSettings, query);
+      case 'nodes':
+      case 'edges':
+        if (query?.queryType === XrayQueryType.getServiceMap) {
+          return [addServiceMapLinks(response, this.instanceSettings, query)];
+        }
+        return [response];
       case 'TraceGraph':
         return parseGraphResponse(response, query, { showRequestCounts: true });
       default:
@@ -233,6 +261,17 @@ export class XrayDataSource extends DataSourceWithBackend<XrayQuery, XrayJsonDat
     }
   }
 
+  private parseLogGroupsResponse(response: DataFrame, region?: string): DataFrame[] {
+    const urlToAwsConsole = `${this.getCloudWatchUrl(region)}#logsV2:log-groups/log-group/`;
+    const nameField = response.fields.find((f) => f.name === 'LogGroupName');
+    if (nameField) {
+      nameField.config.links = [
+        { title: 'Open in CloudWatch Logs', url: urlToAwsConsole + '${__value.raw:percentencode}', targetBlank: true },
+      ];
+    }
+    return [response];
+  }
+
   private parseInsightsResponse(response: DataFrame, region?: string): DataFrame[] {
     const urlToAwsConsole = `${this.getXrayUrl(region)}#/insights/`;
     const idField = response.fields.find((f) => f.name === 'InsightId');
@@ -334,16 +373,29 @@ function parseServiceMapResponse(
   query?: XrayQuery
 ): DataFrame[] {
   const [servicesFrame, edgesFrame] = parseGraphResponse(response, query);
-  const serviceQuery = `service(id(name: "\${__data.fields.title}", type: "\${__data.fields.${NodeGraphDataFrameFieldNames.subTitle}}"))`;
-  servicesFrame.fields[0].config = {
-    links: makeLinks(serviceQuery, instanceSettings, query),
-  };
+  return [
+    addServiceMapLinks(servicesFrame, instanceSettings, query),
+    addServiceMapLinks(edgesFrame, instanceSettings, query),
+  ];
+}
 
-  const edgeQuery = 'edge("${__data.fields.sourceName}", "${__data.fields.targetName}")';
-  edgesFrame.fields[0].config = {
-    links: makeLinks(edgeQuery, instanceSettings, query),
+/**
+ * Adds links to the id field of the node graph frames so users can query traces or statistics of a node or an edge.
+ */
+function addServiceMapLinks(
+  frame: DataFrame,
+  instanceSettings: DataSourceInstanceSettings,
+  query?: XrayQuery
+): DataFrame {
+  const linkQuery =
+    frame.name === 'edges'
+      ? 'edge("${__data.fields.sourceName}", "${__data.fields.targetName}")'
+      : `service(id(name: "\${__data.fields.title}", type: "\${__data.fields.${NodeGraphDataFrameFieldNames.subTitle}}"))`;
+  frame.fields[0].config = {
+    ...frame.fields[0].config,
+    links: makeLinks(linkQuery, instanceSettings, query),
   };
-  return [servicesFrame, edgesFrame];
+  return frame;
 }
 
 export function migrateQuery(query: XrayQuery): XrayQuery {
@@ -390,6 +442,7 @@ function processRequest(request: DataQueryRequest<XrayQuery>, templateSrv: Templ
         } else {
           newTarget.query = target.group.FilterExpression;
         }
+        newTarget.groupFilterApplied = true;
       }
 
       return newTarget;
@@ -110,6 +110,14 @@ describe('QueryEditor', () => {
     [QueryMode.xray, XrayQueryType.getAnalyticsStatusCode, 'HTTP status code'],
     [QueryMode.xray, XrayQueryType.getInsights, 'Insights'],
     [QueryMode.xray, XrayQueryType.getServiceMap, 'Service Map'],
+    [QueryMode.xray, XrayQueryType.getHistogram, 'Histogram'],
+    [QueryMode.xray, XrayQueryType.getTraceRate, 'Trace Rate'],
+    [QueryMode.xray, XrayQueryType.getInsightDetails, 'Insight Details'],
+    [QueryMode.xray, XrayQueryType.getGroups, 'Groups'],
+    [QueryMode.xray, XrayQueryType.getSamplingRules, 'Rules'],
+    [QueryMode.xray, XrayQueryType.getSamplingStatistics, 'Statistics'],
+    [QueryMode.xray, XrayQueryType.getConfiguration, 'Configuration'],
+    [QueryMode.xray, XrayQueryType.getTransactionSearch, 'Transaction Search'],
   ])('renders proper query type option when query mode is %s and query type is %s', async (mode, type, expected) => {
     await renderWithQuery({
       queryMode: mode,
@@ -123,6 +131,7 @@ describe('QueryEditor', () => {
     [QueryMode.services, ServicesQueryType.listServices, 'List services'],
     [QueryMode.services, ServicesQueryType.listServiceOperations, 'List service operations'],
     [QueryMode.services, ServicesQueryType.listServiceDependencies, 'List service dependencies'],
+    [QueryMode.services, ServicesQueryType.listServiceDependents, 'List service dependents'],
     [QueryMode.services, ServicesQueryType.listSLOs, 'List Service Level Objectives (SLO)'],
   ])('renders proper query type option when query mode is %s and query type is %s', async (mode, type, expected) => {
     await renderWithQuery({
@@ -153,6 +162,7 @@ describe('QueryEditor', () => {
       region: 'default',
       queryMode: QueryMode.services,
       serviceQueryType: ServicesQueryType.listServices,
+      attributesFormat: 'json',
     });
   });
 
@@ -168,7 +178,7 @@ describe('QueryEditor', () => {
     });
   });
 
-  it('shows column filter and resolution only if query type is getTimeSeriesServiceStatistics', async () => {
+  it('shows trace statistics options only if query type is getTimeSeriesServiceStatistics', async () => {
     const { rerender } = await renderWithQuery({
       query: '',
       queryMode: QueryMode.xray,
@@ -176,6 +186,8 @@ describe('QueryEditor', () => {
     });
     expect(screen.queryByTestId('column-filter')).toBeNull();
     expect(screen.queryByTestId('resolution')).toBeNull();
+    expect(screen.queryByTestId('group-by')).toBeNull();
+    expect(screen.queryByTestId('forecast')).toBeNull();
 
     await renderWithQuery(
       { query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getTimeSeriesServiceStatistics },
@@ -183,6 +195,8 @@ describe('QueryEditor', () => {
     );
     expect(screen.queryByTestId('column-filter')).not.toBeNull();
     expect(screen.queryByTestId('resolution')).not.toBeNull();
+    expect(screen.queryByTestId('group-by')).not.toBeNull();
+    expect(screen.queryByTestId('forecast')).not.toBeNull();
   });
 
   it('hides query input if query is service map', async () => {
@@ -190,6 +204,53 @@ describe('QueryEditor', () => {
     expect(screen.queryByText(/^Query$/)).toBeNull();
   });
 
+  it('shows service fields instead of query input if query is histogram', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getHistogram });
+    expect(screen.queryByText(/^Query$/)).toBeNull();
+    expect(screen.getByText('Service')).not.toBeNull();
+    expect(screen.getByText('Target service')).not.toBeNull();
+    expect(screen.queryByText('Resolution')).toBeNull();
+  });
+
+  it('shows query input and resolution if query is trace rate', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getTraceRate });
+    expect(screen.getByText(/^Query$/)).not.toBeNull();
+    expect(screen.getByText('Resolution')).not.toBeNull();
+  });
+
+  it('shows insight id instead of query input and group if query is insight details', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getInsightDetails });
+    expect(screen.queryByText(/^Query$/)).toBeNull();
+    expect(screen.queryByText('Group')).toBeNull();
+    expect(screen.getByText('Insight ID')).not.toBeNull();
+  });
+
+  it('hides query input and group if query is groups', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getGroups });
+    expect(screen.queryByText(/^Query$/)).toBeNull();
+    expect(screen.queryByText('Group')).toBeNull();
+  });
+
+  it('shows rule name instead of query input and group if query is sampling', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getSamplingStatistics });
+    expect(screen.queryByText(/^Query$/)).toBeNull();
+    expect(screen.queryByText('Group')).toBeNull();
+    expect(screen.getByText('Rule name')).not.toBeNull();
+  });
+
+  it('shows regions instead of query input and group if query is configuration', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getConfiguration });
+    expect(screen.queryByText(/^Query$/)).toBeNull();
+    expect(screen.queryByText('Group')).toBeNull();
+    expect(screen.getByText('Regions')).not.toBeNull();
+  });
+
+  it('shows query input and limit if query is transaction search', async () => {
+    await renderWithQuery({ query: '', queryMode: QueryMode.xray, queryType: XrayQueryType.getTransactionSearch });
+    expect(screen.getByText(/^Query$/)).not.toBeNull();
+    expect(screen.getByText('Limit')).not.toBeNull();
+  });
+
   it('correctly changes the query type if user fills in trace id (X-Ray format)', async () => {
     const { onChange } = await renderWithQuery({
       query: '',
@@ -364,6 +425,7 @@ describe('QueryEditor', () => {
   it.each([
     ServicesQueryType.listServiceOperations,
     ServicesQueryType.listServiceDependencies,
+    ServicesQueryType.listServiceDependents,
     ServicesQueryType.listSLOs,
   ])('renders service dropdown if query type is %s', async (serviceType) => {
     const mockGetServices = jest.fn(() =>
@@ -420,6 +482,17 @@ describe('QueryEditor', () => {
     });
     expect(screen.getByText('Operation')).not.toBeNull();
   });
+
+  it('renders dependency inputs if list SLOs is filtered by service dependency', async () => {
+    await renderWithQuery({
+      queryMode: QueryMode.services,
+      query: 'test query',
+      serviceQueryType: ServicesQueryType.listSLOs,
+      metricSourceTypes: ['ServiceDependency'],
+    });
+    expect(screen.getByText('Dependency')).not.toBeNull();
+    expect(screen.getByText('Dependency operation')).not.toBeNull();
+  });
 });
 
 function makeDataSource(settings: DataSourceInstanceSettings<XrayJsonData>) {
@@ -1,7 +1,7 @@
 import { css } from '@emotion/css';
 import { QueryEditorProps, SelectableValue, toOption } from '@grafana/data';
 import { EditorField, EditorFieldGroup, EditorRow } from '@grafana/plugin-ui';
-import { InlineSwitch, Select } from '@grafana/ui';
+import { InlineSwitch, Input, MultiSelect, Select } from '@grafana/ui';
 import React from 'react';
 import { ServicesQueryType, XrayJsonData, XrayQuery } from 'types';
 import { XrayDataSource } from 'XRayDataSource';
@@ -17,7 +17,38 @@ const servicesQueryOptions: Array<SelectableValue<ServicesQueryType>> = [
   { label: 'List services', value: ServicesQueryType.listServices },
   { label: 'List service operations', value: ServicesQueryType.listServiceOperations },
   { label: 'List service dependencies', value: ServicesQueryType.listServiceDependencies },
+  { label: 'List service dependents', value: ServicesQueryType.listServiceDependents },
   { label: 'List Service Level Objectives (SLO)', value: ServicesQueryType.listSLOs },
+  { label: 'Service Level Objective (SLO) details', value: ServicesQueryType.getSLODetails },
+  { label: 'Service Level Objective (SLO) history', value: ServicesQueryType.getSLOHistory },
+  { label: 'Service metrics', value: ServicesQueryType.getServiceMetrics },
+  { label: 'Service details', value: ServicesQueryType.getServiceDetails },
+  { label: 'Service dependency graph', value: ServicesQueryType.getServiceDependencyGraph },
+];
+
+const metricTypeOptions: Array<SelectableValue<XrayQuery['metricType']>> = [
+  { label: 'Latency', value: 'Latency' },
+  { label: 'Error', value: 'Error' },
+  { label: 'Fault', value: 'Fault' },
+];
+
+const attributesFormatOptions: Array<SelectableValue<XrayQuery['attributesFormat']>> = [
+  { label: 'JSON', value: 'json' },
+  { label: 'String', value: 'string' },
+];
+
+const directionOptions: Array<SelectableValue<XrayQuery['direction']>> = [
+  { label: 'Dependencies', value: 'downstream' },
+  { label: 'Dependents', value: 'upstream' },
+];
+
+const statistics = ['Average', 'Sum', 'Minimum', 'Maximum', 'p50', 'p90', 'p99'];
+const statisticOptions: Array<SelectableValue<string>> = statistics.map((statistic) => toOption(statistic));
+
+const metricSourceTypeOptions: Array<SelectableValue<string>> = [
+  { label: 'Service operation', value: 'ServiceOperation' },
+  { label: 'Service dependency', value: 'ServiceDependency' },
+  { label: 'CloudWatch metric', value: 'CloudWatchMetric' },
 ];
 
 export function ServiceQueryEditor({ query, onChange, datasource, range }: ServiceQueryEditorFormProps) {
@@ -42,6 +73,24 @@ export function ServiceQueryEditor({ query, onChange, datasource, range }: Servi
 
   const services = useServices(datasource, region, range, query.accountId);
 
+  const isSLOQuery =
+    serviceQueryType === ServicesQueryType.listSLOs ||
+    serviceQueryType === ServicesQueryType.getSLODetails ||
+    serviceQueryType === ServicesQueryType.getSLOHistory;
+  const isServiceMetricsQuery = serviceQueryType === ServicesQueryType.getServiceMetrics;
+  const hasAttributes =
+    serviceQueryType === ServicesQueryType.listServices ||
+    serviceQueryType === ServicesQueryType.listServiceOperations ||
+    serviceQueryType === ServicesQueryType.listServiceDependencies ||
+    serviceQueryType === ServicesQueryType.listServiceDependents ||
+    serviceQueryType === ServicesQueryType.listSLOs ||
+    serviceQueryType === ServicesQueryType.getServiceDetails;
+  const showDependencyFilter =
+    (serviceQueryType === ServicesQueryType.listSLOs && !!query.metricSourceTypes?.includes('ServiceDependency')) ||
+    (isServiceMetricsQuery && !!query.dependencies);
+  const dependencyOption = query.dependencyString
+    ? services.find((option) => option.value === query.dependencyString) ?? toOption(query.dependencyString)
+    : undefined;
   const operations = useOperations(datasource, serviceQueryType, region, range, serviceString);
   const operationOptions: Array<SelectableValue<string>> = (operations || []).map((operation) => ({
     label: operation,
@@ -106,26 +155,211 @@ export function ServiceQueryEditor({ query, onChange, datasource, range }: Servi
                 id="service"
                 options={services}
                 value={serviceName && serviceString ? serviceStringsToOption(serviceName, serviceString) : undefined}
+                // Without a service the SLOs of all services are used
+                isClearable={isSLOQuery}
+                placeholder={isSLOQuery ? 'All services' : undefined}
                 onChange={(value) => {
                   onChange({
                     ...query,
-                    serviceName: value.label,
-                    serviceString: value.value,
+                    serviceName: value?.label,
+                    serviceString: value?.value,
                   });
                 }}
               />
             </EditorField>
           )}
-          {serviceQueryType === ServicesQueryType.listSLOs && (
+          {(isSLOQuery || isServiceMetricsQuery) && (
             <EditorField label="Operation" className="query-keyword" htmlFor="operation">
               <Select
                 id="operation"
                 options={operationOptions}
                 value={query.operationName ? { value: query.operationName, label: query.operationName } : undefined}
+                isClearable={true}
+                placeholder="All operations"
+                onChange={(value) => {
+                  onChange({
+                    ...query,
+                    operationName: value?.value,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {serviceQueryType === ServicesQueryType.listSLOs && (
+            <EditorField label="Metric source" className="query-keyword" htmlFor="metricSourceTypes">
+              <MultiSelect
+                inputId="metricSourceTypes"
+                options={metricSourceTypeOptions}
+                value={query.metricSourceTypes ?? []}
+                closeMenuOnSelect={false}
+                isClearable={true}
+                placeholder="All metric sources"
+                onChange={(values) => {
+                  onChange({
+                    ...query,
+                    metricSourceTypes: values.map((v) => v.value!),
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {isServiceMetricsQuery && (
+            <>
+              <EditorField label="Metric type" className="query-keyword" htmlFor="metricType">
+                <Select
+                  id="metricType"
+                  options={metricTypeOptions}
+                  value={query.metricType}
+                  onChange={(value) => {
+                    onChange({
+                      ...query,
+                      metricType: value.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField label="Statistic" className="query-keyword" htmlFor="statistic">
+                <Select
+                  id="statistic"
+                  options={statisticOptions}
+                  value={query.statistic}
+                  allowCustomValue={true}
+                  isClearable={true}
+                  placeholder={query.metricType === 'Latency' ? 'Average' : 'Sum'}
+                  onChange={(value) => {
+                    onChange({
+                      ...query,
+                      statistic: value?.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField label="Dependencies" className="query-keyword" htmlFor="dependencies">
+                <InlineSwitch
+                  id="dependencies"
+                  value={query.dependencies ?? false}
+                  onChange={() => {
+                    onChange({
+                      ...query,
+                      dependencies: !(query.dependencies ?? false),
+                    });
+                  }}
+                />
+              </EditorField>
+            </>
+          )}
+          {showDependencyFilter && (
+            <>
+              <EditorField label="Dependency" className="query-keyword" htmlFor="dependency">
+                <Select
+                  id="dependency"
+                  options={services}
+                  value={dependencyOption}
+                  allowCustomValue={true}
+                  isClearable={true}
+                  placeholder="All dependencies"
+                  onChange={(value) => {
+                    onChange({
+                      ...query,
+                      dependencyString: value?.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField label="Dependency operation" className="query-keyword" htmlFor="dependencyOperationName">
+                <Input
+                  id="dependencyOperationName"
+                  defaultValue={query.dependencyOperationName}
+                  placeholder="All operations"
+                  onBlur={(e) => {
+                    onChange({
+                      ...query,
+                      dependencyOperationName: e.currentTarget.value,
+                    });
+                  }}
+                />
+              </EditorField>
+            </>
+          )}
+          {serviceQueryType === ServicesQueryType.getSLOHistory && (
+            <EditorField
+              label="Burn rate threshold"
+              tooltip="Also mark the SLO as breached when the burn rate is above this. At a burn rate of 1 the budget lasts the whole interval."
+              className="query-keyword"
+              htmlFor="burnRateThreshold"
+            >
+              <Input
+                id="burnRateThreshold"
+                type="number"
+                min={0}
+                defaultValue={query.burnRateThreshold}
+                placeholder="Breaches only"
+                onBlur={(e) => {
+                  const value = parseFloat(e.currentTarget.value);
+                  onChange({
+                    ...query,
+                    burnRateThreshold: isNaN(value) ? undefined : value,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {serviceQueryType === ServicesQueryType.getServiceDependencyGraph && (
+            <>
+              <EditorField
+                label="Depth"
+                tooltip="Number of hops to walk from the service, at most 5."
+                className="query-keyword"
+                htmlFor="depth"
+              >
+                <Input
+                  id="depth"
+                  type="number"
+                  min={1}
+                  max={5}
+                  defaultValue={query.depth}
+                  placeholder="2"
+                  onBlur={(e) => {
+                    const value = parseInt(e.currentTarget.value, 10);
+                    onChange({
+                      ...query,
+                      depth: isNaN(value) ? undefined : value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField label="Direction" className="query-keyword" htmlFor="direction">
+                <Select
+                  id="direction"
+                  options={directionOptions}
+                  value={query.direction}
+                  isClearable={true}
+                  placeholder="Both"
+                  onChange={(value) => {
+                    onChange({
+                      ...query,
+                      direction: value?.value,
+                    });
+                  }}
+                />
+              </EditorField>
+            </>
+          )}
+          {hasAttributes && (
+            <EditorField
+              label="Attributes format"
+              tooltip="Format of the key attributes and dimensions. JSON fields can be split into columns with the Extract fields transformation."
+              className="query-keyword"
+              htmlFor="attributesFormat"
+            >
+              <Select
+                id="attributesFormat"
+                options={attributesFormatOptions}
+                value={query.attributesFormat ?? 'string'}
                 onChange={(value) => {
                   onChange({
                     ...query,
-                    operationName: value.value,
+                    attributesFormat: value.value,
                   });
                 }}
               />
@@ -1,17 +1,24 @@
 import React from 'react';
 import { css } from '@emotion/css';
-import { QueryEditorProps, ScopedVars } from '@grafana/data';
-import { MultiSelect, Select, ButtonCascader } from '@grafana/ui';
-import { Group, XrayJsonData, XrayQuery, XrayQueryType } from '../../types';
+import { QueryEditorProps, ScopedVars, SelectableValue } from '@grafana/data';
+import { InlineSwitch, Input, MultiSelect, Select, ButtonCascader } from '@grafana/ui';
+import { Group, Region, XrayJsonData, XrayQuery, XrayQueryType } from '../../types';
 import {
   QueryTypeOption,
   columnNames,
+  configurationOption,
   dummyAllGroup,
+  groupsOption,
+  histogramOption,
+  insightDetailsOption,
   insightsOption,
   queryTypeOptions,
+  samplingOption,
   serviceMapOption,
   traceListOption,
+  traceRateOption,
   traceStatisticsOption,
+  transactionSearchOption,
 } from './constants';
 import { XrayDataSource } from '../../XRayDataSource';
 import { getTemplateSrv } from '@grafana/runtime';
@@ -73,6 +80,29 @@ export function queryTypeOptionToQueryType(selected: string[], query: string, sc
   }
 }
 
+// Query types that do not use the filter expression
+const optionsWithoutQuery = [
+  insightsOption,
+  insightDetailsOption,
+  serviceMapOption,
+  histogramOption,
+  groupsOption,
+  samplingOption,
+  configurationOption,
+];
+// Query types that do not use the group
+const optionsWithoutGroup = [insightDetailsOption, groupsOption, samplingOption, configurationOption];
+
+const groupByOptions: Array<SelectableValue<XrayQuery['groupBy']>> = [
+  { label: 'Service', value: 'service' },
+  { label: 'Edge', value: 'edge' },
+];
+
+const histogramTypeOptions: Array<SelectableValue<XrayQuery['histogram']>> = [
+  { label: 'Response time', value: 'responseTime' },
+  { label: 'Duration', value: 'duration' },
+];
+
 const getStyles = () => ({
   queryParamsRow: css`
     flex-wrap: wrap;
@@ -90,6 +120,7 @@ const getStyles = () => ({
 
 export type XrayQueryEditorFormProps = QueryEditorProps<XrayDataSource, XrayQuery, XrayJsonData> & {
   groups: Group[];
+  regions: Region[];
 };
 export function XRayQueryEditor({
   query,
@@ -97,6 +128,7 @@ export function XRayQueryEditor({
   datasource,
   onRunQuery,
   groups,
+  regions,
   range,
   data,
 }: XrayQueryEditorFormProps) {
@@ -126,22 +158,24 @@ export function XRayQueryEditor({
               {selectedOptions[selectedOptions.length - 1].label}
             </ButtonCascader>
           </EditorField>
-          <EditorField label="Group" className={`query-keyword ${styles.formFieldStyles}`} htmlFor="groupName">
-            <Select
-              id="groupName"
-              value={query.group?.GroupName}
-              options={allGroups.map((group: Group) => ({
-                value: group.GroupARN,
-                label: group.GroupName,
-              }))}
-              onChange={(value) => {
-                onChange({
-                  ...query,
-                  group: allGroups.find((g: Group) => g.GroupARN === value.value),
-                } as any);
-              }}
-            />
-          </EditorField>
+          {!optionsWithoutGroup.includes(selectedOptions[0]) && (
+            <EditorField label="Group" className={`query-keyword ${styles.formFieldStyles}`} htmlFor="groupName">
+              <Select
+                id="groupName"
+                value={query.group?.GroupName}
+                options={allGroups.map((group: Group) => ({
+                  value: group.GroupARN,
+                  label: group.GroupName,
+                }))}
+                onChange={(value) => {
+                  onChange({
+                    ...query,
+                    group: allGroups.find((g: Group) => g.GroupARN === value.value),
+                  } as any);
+                }}
+              />
+            </EditorField>
+          )}
           {[serviceMapOption].includes(selectedOptions[0]) && (
             <AccountIdDropdown
               datasource={datasource}
@@ -170,6 +204,58 @@ export function XRayQueryEditor({
               />
             </EditorField>
           )}
+          {selectedOptions[0] === insightDetailsOption && (
+            <EditorField
+              label="Insight ID"
+              tooltip="The InsightId from the Insights table."
+              className={`query-keyword ${styles.formFieldStyles}`}
+              htmlFor="insightId"
+            >
+              <Input
+                id="insightId"
+                defaultValue={query.insightId}
+                onBlur={(e) => {
+                  onChange({
+                    ...query,
+                    insightId: e.currentTarget.value,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {selectedOptions[0] === samplingOption && (
+            <EditorField label="Rule name" className={`query-keyword ${styles.formFieldStyles}`} htmlFor="ruleName">
+              <Input
+                id="ruleName"
+                defaultValue={query.ruleName}
+                placeholder="All rules"
+                onBlur={(e) => {
+                  onChange({
+                    ...query,
+                    ruleName: e.currentTarget.value,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {selectedOptions[0] === configurationOption && (
+            <EditorField
+              label="Regions"
+              tooltip="Regions to compare the configuration of. Uses the query region when empty."
+              className={`query-keyword ${styles.formFieldStyles}`}
+              htmlFor="regions"
+            >
+              <MultiSelect
+                inputId="regions"
+                options={regions.map((region) => ({ label: region.label, value: region.value }))}
+                value={query.regions ?? []}
+                onChange={(values) => onChange({ ...query, regions: values.map((v) => v.value!) })}
+                closeMenuOnSelect={false}
+                isClearable={true}
+                placeholder="Query region"
+              />
+            </EditorField>
+          )}
           {selectedOptions[0] === traceStatisticsOption && (
             <EditorField
               label="Resolution"
@@ -190,10 +276,218 @@ export function XRayQueryEditor({
               />
             </EditorField>
           )}
+          {selectedOptions[0] === traceStatisticsOption && (
+            <EditorField
+              label="Group by"
+              tooltip="Return a separate series for each service or edge of the service map matching the query."
+              className={`query-keyword ${styles.formFieldStyles}`}
+              htmlFor="groupBy"
+              data-testid="group-by"
+            >
+              <Select
+                id="groupBy"
+                options={groupByOptions}
+                value={query.groupBy}
+                isClearable={true}
+                placeholder="None"
+                onChange={(value) => {
+                  onChange({
+                    ...query,
+                    groupBy: value?.value,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {selectedOptions[0] === traceStatisticsOption && (
+            <EditorField
+              label="Forecast"
+              tooltip="Add the upper and lower bounds of the fault count X-Ray forecasts for the service."
+              className={`query-keyword ${styles.formFieldStyles}`}
+              htmlFor="forecast"
+              data-testid="forecast"
+            >
+              <InlineSwitch
+                id="forecast"
+                value={query.forecast ?? false}
+                onChange={() => {
+                  onChange({
+                    ...query,
+                    forecast: !(query.forecast ?? false),
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {selectedOptions[0] === transactionSearchOption && (
+            <EditorField
+              label="Limit"
+              tooltip="The maximum number of traces to retrieve, up to 5."
+              className={`query-keyword ${styles.formFieldStyles}`}
+              htmlFor="limit"
+            >
+              <Input
+                id="limit"
+                type="number"
+                min={1}
+                max={5}
+                defaultValue={query.limit}
+                placeholder="5"
+                onBlur={(e) => {
+                  const limit = parseInt(e.currentTarget.value, 10);
+                  onChange({
+                    ...query,
+                    limit: limit > 0 ? limit : undefined,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {selectedOptions[0] === traceRateOption && (
+            <EditorField
+              label="Resolution"
+              tooltip="Time bucket size in seconds. Uses the panel interval when empty."
+              className={`query-keyword ${styles.formFieldStyles}`}
+              htmlFor="traceRateResolution"
+            >
+              <Input
+                id="traceRateResolution"
+                type="number"
+                min={1}
+                defaultValue={query.resolution}
+                placeholder="auto"
+                onBlur={(e) => {
+                  const resolution = parseInt(e.currentTarget.value, 10);
+                  onChange({
+                    ...query,
+                    resolution: resolution > 0 ? resolution : undefined,
+                  });
+                }}
+              />
+            </EditorField>
+          )}
+          {selectedOptions[0] === histogramOption && (
+            <>
+              <EditorField label="Service" className={`query-keyword ${styles.formFieldStyles}`} htmlFor="serviceName">
+                <Input
+                  id="serviceName"
+                  defaultValue={query.serviceName}
+                  onBlur={(e) => {
+                    onChange({
+                      ...query,
+                      serviceName: e.currentTarget.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField
+                label="Service type"
+                className={`query-keyword ${styles.formFieldStyles}`}
+                htmlFor="serviceType"
+              >
+                <Input
+                  id="serviceType"
+                  defaultValue={query.serviceType}
+                  placeholder="Any type"
+                  onBlur={(e) => {
+                    onChange({
+                      ...query,
+                      serviceType: e.currentTarget.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField
+                label="Target service"
+                tooltip="Set to get the histogram of the edge from the service to this service."
+                className={`query-keyword ${styles.formFieldStyles}`}
+                htmlFor="targetServiceName"
+              >
+                <Input
+                  id="targetServiceName"
+                  defaultValue={query.targetServiceName}
+                  placeholder="None"
+                  onBlur={(e) => {
+                    onChange({
+                      ...query,
+                      targetServiceName: e.currentTarget.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              {!!query.targetServiceName && (
+                <EditorField
+                  label="Target service type"
+                  className={`query-keyword ${styles.formFieldStyles}`}
+                  htmlFor="targetServiceType"
+                >
+                  <Input
+                    id="targetServiceType"
+                    defaultValue={query.targetServiceType}
+                    placeholder="Any type"
+                    onBlur={(e) => {
+                      onChange({
+                        ...query,
+                        targetServiceType: e.currentTarget.value,
+                      });
+                    }}
+                  />
+                </EditorField>
+              )}
+              <EditorField
+                label="Histogram type"
+                className={`query-keyword ${styles.formFieldStyles}`}
+                htmlFor="histogram"
+              >
+                <Select
+                  id="histogram"
+                  options={histogramTypeOptions}
+                  value={query.histogram ?? 'responseTime'}
+                  onChange={(value) => {
+                    onChange({
+                      ...query,
+                      histogram: value.value,
+                    });
+                  }}
+                />
+              </EditorField>
+              <EditorField label="Over time" className={`query-keyword ${styles.formFieldStyles}`} htmlFor="overTime">
+                <InlineSwitch
+                  id="overTime"
+                  value={query.overTime ?? false}
+                  onChange={() => {
+                    onChange({
+                      ...query,
+                      overTime: !(query.overTime ?? false),
+                    });
+                  }}
+                />
+              </EditorField>
+              {query.overTime && (
+                <EditorField
+                  label="Resolution"
+                  className={`query-keyword ${styles.formFieldStyles}`}
+                  htmlFor="histogramResolution"
+                >
+                  <Select
+                    id="histogramResolution"
+                    value={(query.resolution ?? 60).toString() + 's'}
+                    options={['60s', '300s'].map((val) => ({ value: val, label: val }))}
+                    onChange={({ value }) => {
+                      onChange({
+                        ...query,
+                        resolution: parseInt(value!, 10),
+                      });
+                    }}
+                  />
+                </EditorField>
+              )}
+            </>
+          )}
           <XrayLinks datasource={datasource} query={query} range={range} />
         </EditorFieldGroup>
       </EditorRow>
-      {![insightsOption, serviceMapOption].includes(selectedOptions[0]) && (
+      {!optionsWithoutQuery.includes(selectedOptions[0]) && (
         <EditorRow>
           <QuerySection
             query={query}
@@ -13,22 +13,77 @@ export const insightsOption: QueryTypeOption = {
   value: 'insights',
   queryType: XrayQueryType.getInsights,
 };
+export const insightDetailsOption: QueryTypeOption = {
+  label: 'Insight Details',
+  value: 'insightDetails',
+  queryType: XrayQueryType.getInsightDetails,
+};
 export const serviceMapOption: QueryTypeOption = {
   label: 'Service Map',
   value: 'serviceMap',
   queryType: XrayQueryType.getServiceMap,
 };
 
+export const histogramOption: QueryTypeOption = {
+  label: 'Histogram',
+  value: 'histogram',
+  queryType: XrayQueryType.getHistogram,
+};
+
+export const groupsOption: QueryTypeOption = {
+  label: 'Groups',
+  value: 'groups',
+  queryType: XrayQueryType.getGroups,
+};
+
+export const samplingOption: QueryTypeOption = {
+  label: 'Sampling',
+  value: 'sampling',
+  children: [
+    {
+      value: 'rules',
+      label: 'Rules',
+      queryType: XrayQueryType.getSamplingRules,
+    },
+    {
+      value: 'statistics',
+      label: 'Statistics',
+      queryType: XrayQueryType.getSamplingStatistics,
+    },
+  ],
+};
+
+export const configurationOption: QueryTypeOption = {
+  label: 'Configuration',
+  value: 'configuration',
+  queryType: XrayQueryType.getConfiguration,
+};
+
+export const transactionSearchOption: QueryTypeOption = {
+  label: 'Transaction Search',
+  value: 'transactionSearch',
+  queryType: XrayQueryType.getTransactionSearch,
+};
+
 export const traceStatisticsOption: QueryTypeOption = {
   label: 'Trace Statistics',
   value: 'traceStatistics',
   queryType: XrayQueryType.getTimeSeriesServiceStatistics,
 };
 
+export const traceRateOption: QueryTypeOption = {
+  label: 'Trace Rate',
+  value: 'traceRate',
+  queryType: XrayQueryType.getTraceRate,
+};
+
 export const queryTypeOptions: QueryTypeOption[] = [
   traceListOption,
+  transactionSearchOption,
   traceStatisticsOption,
+  traceRateOption,
   insightsOption,
+  insightDetailsOption,
   {
     label: 'Trace Analytics',
     value: 'traceAnalytics',
@@ -115,6 +170,10 @@ export const queryTypeOptions: QueryTypeOption[] = [
     ],
   },
   serviceMapOption,
+  histogramOption,
+  groupsOption,
+  samplingOption,
+  configurationOption,
 ];
 
 export const columnNames: { [key: string]: string } = {
@@ -124,6 +183,13 @@ export const columnNames: { [key: string]: string } = {
   OkCount: 'Success Count',
   TotalCount: 'Total Count',
   'Computed.AverageResponseTime': 'Average Response Time',
+  'Computed.P50ResponseTime': 'p50 Response Time',
+  'Computed.P90ResponseTime': 'p90 Response Time',
+  'Computed.P99ResponseTime': 'p99 Response Time',
+  'Computed.ErrorRate': 'Error Rate %',
+  'Computed.FaultRate': 'Fault Rate %',
+  'Computed.ThrottleRate': 'Throttle Rate %',
+  'Computed.RequestsPerSecond': 'Requests Per Second',
 };
 
 // Dummy group that can be selected only in insights;
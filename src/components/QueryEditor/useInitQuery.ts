@@ -46,6 +46,8 @@ export function useInitQuery(
         ...newQuery,
         serviceQueryType: ServicesQueryType.listServices,
         region: 'default',
+        // Queries saved before the JSON format existed keep the string format
+        attributesFormat: 'json',
       };
       updated = true;
     } else {
@@ -15,7 +15,12 @@ export function useOperations(
   service?: string
 ): string[] | undefined {
   const result = useAsync(async () => {
-    if (serviceQueryType === ServicesQueryType.listSLOs && service) {
+    const hasOperations =
+      serviceQueryType === ServicesQueryType.listSLOs ||
+      serviceQueryType === ServicesQueryType.getSLODetails ||
+      serviceQueryType === ServicesQueryType.getSLOHistory ||
+      serviceQueryType === ServicesQueryType.getServiceMetrics;
+    if (hasOperations && service) {
       return datasource.getOperations(region, range, service);
     } else {
       return Promise.resolve([]);
@@ -25,16 +25,60 @@ export interface XrayQuery extends DataQuery {
   // Interval of the getTimeSeriesServiceStatistics aggregation time bucket
   resolution?: number;
 
+  // Used in case of getTimeSeriesServiceStatistics to return separate series for each service or edge
+  groupBy?: 'service' | 'edge';
+
+  // Used in case of getTimeSeriesServiceStatistics to add the forecasted fault count bounds
+  forecast?: boolean;
+
+  // Used in case of getAlertingMetric to select the metric
+  metric?: AlertingMetric;
+
   // Used in case of getInsights to filter by state
   state?: string;
   group?: Group;
 
+  // Set when the group filter expression was added to the query so the backend does not add it again
+  groupFilterApplied?: boolean;
+
+  // Used in case of getInsightDetails to select the insight
+  insightId?: string;
+
+  // Used in case of getSamplingRules and getSamplingStatistics to select a single rule
+  ruleName?: string;
+
+  // Used in case of getTransactionSearch to limit the number of retrieved traces
+  limit?: number;
+
   // Can be used to override the default region set in data source config
   region?: string;
 
+  // Used in case of getConfiguration to compare more regions
+  regions?: string[];
+
   // used to manually filter service map queries by account ids
   accountIds?: string[];
 
+  // Used to get the service map as one JSON string per service instead of node graph frames
+  legacyFormat?: boolean;
+
+  // Used to show only part of the service map around the focused service
+  focusService?: string;
+  focusDepth?: number;
+  focusDirection?: 'upstream' | 'downstream';
+  excludeTypes?: string[];
+  collapseClients?: boolean;
+
+  // Used to compare the service map with the same time range shifted back by this duration, e.g. 1d
+  compareTimeShift?: string;
+
+  // Used to select the service or edge for getHistogram
+  targetServiceName?: string;
+  targetServiceType?: string;
+  serviceType?: string;
+  histogram?: 'responseTime' | 'duration';
+  overTime?: boolean;
+
   // if linked accounts should be used for a service query
   includeLinkedAccounts?: boolean;
 
@@ -51,6 +95,24 @@ export interface XrayQuery extends DataQuery {
 
   // Used to get results for List Service Level Objectives queries in Application Signals
   operationName?: string;
+  metricSourceTypes?: string[];
+  dependencyString?: string;
+  dependencyOperationName?: string;
+
+  // Used to mark the SLO history as breached when the budget is used faster than this
+  burnRateThreshold?: number;
+
+  // Used to get the CloudWatch metrics of a service in Application Signals
+  metricType?: 'Latency' | 'Error' | 'Fault';
+  statistic?: string;
+  dependencies?: boolean;
+
+  // Used to walk the Application Signals dependency graph of a service
+  depth?: number;
+  direction?: 'upstream' | 'downstream';
+
+  // Format of the key attributes and dimensions in the Application Signals frames, string if not set
+  attributesFormat?: 'json' | 'string';
 }
 
 export enum VariableQueryType {
@@ -88,13 +150,38 @@ export enum XrayQueryType {
   getAnalyticsStatusCode = 'getAnalyticsStatusCode',
   getInsights = 'getInsights',
   getServiceMap = 'getServiceMap',
+  getHistogram = 'getHistogram',
+  getAlertingMetric = 'getAlertingMetric',
+  getTraceRate = 'getTraceRate',
+  getInsightDetails = 'getInsightDetails',
+  getInsightAnnotations = 'getInsightAnnotations',
+  getGroups = 'getGroups',
+  getSamplingRules = 'getSamplingRules',
+  getSamplingStatistics = 'getSamplingStatistics',
+  getConfiguration = 'getConfiguration',
+  getTransactionSearch = 'getTransactionSearch',
+}
+
+// Needs to match datasource AlertingMetric* constants in backend code
+export enum AlertingMetric {
+  activeInsights = 'activeInsights',
+  rootCauseFaultPercent = 'rootCauseFaultPercent',
+  traceCount = 'traceCount',
+  sloAttainment = 'sloAttainment',
 }
 
 export enum ServicesQueryType {
   listServices = 'listServices',
   listServiceOperations = 'listServiceOperations',
   listServiceDependencies = 'listServiceDependencies',
+  listServiceDependents = 'listServiceDependents',
   listSLOs = 'listServiceLevelObjectives',
+  getSLODetails = 'getServiceLevelObjectiveDetails',
+  getSLOHistory = 'getServiceLevelObjectiveHistory',
+  getSLOBreaches = 'getServiceLevelObjectiveBreaches',
+  getServiceMetrics = 'getServiceMetrics',
+  getServiceDetails = 'getServiceDetails',
+  getServiceDependencyGraph = 'getServiceDependencyGraph',
 }
 
 export enum QueryMode {